@@ -44,11 +44,35 @@ func main() {
 
     // init以太坊客户端
     ethCfg := &ethereum.EthereumConfig{
-        RPCEndpoint:     cfg.Ethereum.RPCEndpoint,
-        ChainID:         cfg.Ethereum.ChainID,
-        UniswapV2Router: cfg.Ethereum.UniswapV2Router,
-        UniswapV3Router: cfg.Ethereum.UniswapV3Router,
-        WETHAddress:     cfg.Ethereum.WETHAddress,
+        RPCEndpoint:      cfg.Ethereum.RPCEndpoint,
+        ChainID:          cfg.Ethereum.ChainID,
+        UniswapV2Router:  cfg.Ethereum.UniswapV2Router,
+        UniswapV2Factory: cfg.Ethereum.UniswapV2Factory,
+        UniswapV3Router:  cfg.Ethereum.UniswapV3Router,
+        UniswapV3Quoter:  cfg.Ethereum.UniswapV3Quoter,
+        UniswapV3Factory: cfg.Ethereum.UniswapV3Factory,
+        WETHAddress:      cfg.Ethereum.WETHAddress,
+        BaseTokens:       cfg.Ethereum.BaseTokens,
+
+        PriceImpactWarnPercent:  cfg.Ethereum.PriceImpactWarnPercent,
+        PriceImpactBlockPercent: cfg.Ethereum.PriceImpactBlockPercent,
+        QuoteTTL:                time.Duration(cfg.Ethereum.QuoteTTLSeconds) * time.Second,
+
+        RelayURL:            cfg.Ethereum.RelayURL,
+        RelayMode:           cfg.Ethereum.RelayMode,
+        RelayAuthKey:        cfg.Ethereum.RelayAuthKey,
+        RelayFallbackBlocks: cfg.Ethereum.RelayFallbackBlocks,
+
+        Multicall3Address: cfg.Ethereum.Multicall3Address,
+        Watchlist:         cfg.Ethereum.Watchlist,
+
+        UniversalRouter: cfg.Ethereum.UniversalRouter,
+        Permit2Address:  cfg.Ethereum.Permit2Address,
+
+        ENSRegistry: cfg.Ethereum.ENSRegistry,
+        ENSCacheTTL: time.Duration(cfg.Ethereum.ENSCacheTTLSeconds) * time.Second,
+
+        AddressChecksum: cfg.Ethereum.AddressChecksum,
     }
     ethClient, err := ethereum.NewEthereumClient(ethCfg, walletMgr, logger)
     if err != nil {
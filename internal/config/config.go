@@ -21,11 +21,37 @@ type ServerConfig struct {
 }
 
 type EthereumConfig struct {
-    RPCEndpoint     string `mapstructure:"rpc_endpoint"`
-    ChainID         int64  `mapstructure:"chain_id"`
-    UniswapV2Router string `mapstructure:"uniswap_v2_router"`
-    UniswapV3Router string `mapstructure:"uniswap_v3_router"`
-    WETHAddress     string `mapstructure:"weth_address"`
+    RPCEndpoint      string   `mapstructure:"rpc_endpoint"`
+    ChainID          int64    `mapstructure:"chain_id"`
+    UniswapV2Router  string   `mapstructure:"uniswap_v2_router"`
+    UniswapV2Factory string   `mapstructure:"uniswap_v2_factory"`
+    UniswapV3Router  string   `mapstructure:"uniswap_v3_router"`
+    UniswapV3Quoter  string   `mapstructure:"uniswap_v3_quoter"`
+    UniswapV3Factory string   `mapstructure:"uniswap_v3_factory"`
+    WETHAddress      string   `mapstructure:"weth_address"`
+    BaseTokens       []string `mapstructure:"base_tokens"`
+
+    PriceImpactWarnPercent  float64 `mapstructure:"price_impact_warn_percent"`
+    PriceImpactBlockPercent float64 `mapstructure:"price_impact_block_percent"`
+    QuoteTTLSeconds         int     `mapstructure:"quote_ttl_seconds"`
+
+    // 私有中继 (Flashbots 风格)，relay_url 为空时不启用
+    RelayURL            string `mapstructure:"relay_url"`
+    RelayMode           string `mapstructure:"relay_mode"`
+    RelayAuthKey        string `mapstructure:"relay_auth_key"`
+    RelayFallbackBlocks uint64 `mapstructure:"relay_fallback_blocks"`
+
+    Multicall3Address string   `mapstructure:"multicall3_address"`
+    Watchlist         []string `mapstructure:"watchlist"`
+
+    // Permit2 签名授权通过 Universal Router 执行，universal_router 为空时不启用
+    UniversalRouter string `mapstructure:"universal_router"`
+    Permit2Address  string `mapstructure:"permit2_address"`
+
+    ENSRegistry        string `mapstructure:"ens_registry"`
+    ENSCacheTTLSeconds int    `mapstructure:"ens_cache_ttl_seconds"`
+
+    AddressChecksum string `mapstructure:"address_checksum"`
 }
 
 type WalletConfig struct {
@@ -75,8 +101,21 @@ func setDefaults() {
     viper.SetDefault("server.port", 8080)
     viper.SetDefault("ethereum.chain_id", 1) // Mainnet
     viper.SetDefault("ethereum.uniswap_v2_router", "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+    viper.SetDefault("ethereum.uniswap_v2_factory", "0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f")
     viper.SetDefault("ethereum.uniswap_v3_router", "0xE592427A0AEce92De3Edee1F18E0157C05861564")
+    viper.SetDefault("ethereum.uniswap_v3_quoter", "0x61fFE014bA17989E743c5F6cB21bF9697530B21e")
+    viper.SetDefault("ethereum.uniswap_v3_factory", "0x1F98431c8aD98523631AE4a59f267346ea31F984")
     viper.SetDefault("ethereum.weth_address", "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+    viper.SetDefault("ethereum.universal_router", "0x66a9893cC07D91D95644AEDD05D03f95e1dBA8Af")
+    viper.SetDefault("ethereum.base_tokens", []string{"ETH", "USDC", "USDT", "DAI"}) // 多跳路由的中间代币
+    viper.SetDefault("ethereum.price_impact_warn_percent", 1.0)
+    viper.SetDefault("ethereum.price_impact_block_percent", 3.0)
+    viper.SetDefault("ethereum.quote_ttl_seconds", 60)
+    viper.SetDefault("ethereum.relay_mode", "private")
+    viper.SetDefault("ethereum.relay_fallback_blocks", 25)
+    viper.SetDefault("ethereum.watchlist", []string{"USDC", "USDT", "DAI", "WBTC", "UNI", "LINK", "AAVE"})
+    viper.SetDefault("ethereum.ens_cache_ttl_seconds", 300)
+    viper.SetDefault("ethereum.address_checksum", "warn")
     viper.SetDefault("logging.level", "info")
 }
 
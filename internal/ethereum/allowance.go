@@ -0,0 +1,178 @@
+//ERC20 授权
+package ethereum
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+
+    "github.com/ethereum/go-ethereum/accounts/abi/bind"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/common/math"
+
+    "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
+)
+
+// 授权不足时 router 调用会 revert，无法预估 gas，使用经验值
+const (
+    v2SwapGasPerHop   = 100000
+    v2SwapGasBase     = 50000
+    v3SwapGasOverhead = 60000
+)
+
+const (
+    ApprovalModeExact     = "exact"
+    ApprovalModeUnlimited = "unlimited"
+)
+
+// 卖出代币所需的授权，router 的 allowance 不足时返回
+type TokenApproval struct {
+    Token     string          `json:"token"`
+    Spender   string          `json:"spender"`
+    Required  decimal.Decimal `json:"required"`
+    Allowance decimal.Decimal `json:"allowance"`
+
+    token    common.Address
+    spender  common.Address
+    amount   *big.Int
+    decimals int
+}
+
+type ApproveTokenRequest struct {
+    // swap_tokens 返回的 quote_id，授权的代币、spender 和数量都取自该报价
+    QuoteID string `json:"quote_id"`
+    Mode    string `json:"mode"`
+}
+
+type ApproveTokenResponse struct {
+    QuoteID    string  `json:"quote_id"`
+    Token      string  `json:"token,omitempty"`
+    Spender    string  `json:"spender,omitempty"`
+    Amount     string  `json:"amount,omitempty"`
+    Mode       string  `json:"mode"`
+    TxHash     string  `json:"tx_hash,omitempty"`
+    Nonce      uint64  `json:"nonce"`
+    Submission string  `json:"submission,omitempty"`
+    Success    bool    `json:"success"`
+    Error      *string `json:"error,omitempty"`
+}
+
+// 检查钱包对 spender 的授权是否覆盖输入数量 (精确输出时为最多输入)，ETH 输入通过 value 发送不需要授权
+func (ec *EthereumClient) checkAllowance(ctx context.Context, pair *swapPair, spender common.Address, amount *big.Int) (*TokenApproval, error) {
+    if pair.From == common.HexToAddress(ec.config.WETHAddress) {
+        return nil, nil
+    }
+
+    allowance, err := ec.tokenAllowance(ctx, pair.From, ec.walletMgr.GetAddress(), spender)
+    if err != nil {
+        return nil, err
+    }
+    if allowance.Cmp(amount) >= 0 {
+        return nil, nil
+    }
+
+    return &TokenApproval{
+        Token:     pair.From.Hex(),
+        Spender:   spender.Hex(),
+        Required:  decimal.FormatBalance(amount, pair.FromDecimals),
+        Allowance: decimal.FormatBalance(allowance, pair.FromDecimals),
+        token:     pair.From,
+        spender:   spender,
+        amount:    amount,
+        decimals:  pair.FromDecimals,
+    }, nil
+}
+
+func (ec *EthereumClient) tokenAllowance(ctx context.Context, token, owner, spender common.Address) (*big.Int, error) {
+    caller := NewERC20Caller(token, ec.caller)
+    allowance, err := caller.Allowance(&bind.CallOpts{Context: ctx}, owner, spender)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get allowance for %s: %w", token.Hex(), err)
+    }
+    return allowance, nil
+}
+
+// 为报价中的卖出代币向 router 发送 approve 交易
+func (ec *EthereumClient) ApproveToken(ctx context.Context, req *ApproveTokenRequest) (*ApproveTokenResponse, error) {
+    mode := req.Mode
+    if mode == "" {
+        mode = ApprovalModeExact
+    }
+    resp := &ApproveTokenResponse{
+        QuoteID: req.QuoteID,
+        Mode:    mode,
+    }
+    if mode != ApprovalModeExact && mode != ApprovalModeUnlimited {
+        resp.Error = stringPtr(fmt.Sprintf("mode must be %q or %q", ApprovalModeExact, ApprovalModeUnlimited))
+        return resp, nil
+    }
+
+    stored, err := ec.quotes.get(req.QuoteID)
+    if err != nil {
+        resp.Error = stringPtr(err.Error())
+        return resp, nil
+    }
+    approval := stored.Response.Approval
+    if approval == nil {
+        resp.Error = stringPtr("quote does not require an approval")
+        return resp, nil
+    }
+    resp.Token = approval.Token
+    resp.Spender = approval.Spender
+
+    amount := approval.amount
+    resp.Amount = decimal.FormatBalance(amount, approval.decimals).String()
+    if mode == ApprovalModeUnlimited {
+        amount = math.MaxBig256
+        resp.Amount = "unlimited"
+    }
+
+    data, err := erc20ABI.Pack("approve", approval.spender, amount)
+    if err != nil {
+        return nil, fmt.Errorf("failed to build approve data: %w", err)
+    }
+
+    gasEstimate, err := ec.EstimateGas(ctx, ec.walletMgr.GetAddress(), &approval.token, big.NewInt(0), data)
+    if err != nil {
+        // 部分代币 (如 USDT) 要求先把已有授权清零才能修改
+        resp.Error = stringPtr(fmt.Sprintf("failed to estimate approve gas: %v", err))
+        return resp, nil
+    }
+
+    tx, err := ec.sendTransaction(ctx, req.QuoteID, approval.token, data, big.NewInt(0), gasEstimate)
+    if err != nil {
+        resp.Error = stringPtr(err.Error())
+        return resp, nil
+    }
+
+    resp.TxHash = tx.Hash().Hex()
+    resp.Nonce = tx.Nonce()
+    resp.Submission = ec.txs.submission(tx.Hash())
+    resp.Success = true
+    return resp, nil
+}
+
+// 授权不足时跳过 EstimateGas，返回经验 gas 值
+func (ec *EthereumClient) estimateSwapGas(ctx context.Context, router common.Address, value *big.Int, data []byte, approval *TokenApproval, fallback uint64) (uint64, error) {
+    if approval != nil {
+        return fallback, nil
+    }
+    gasEstimate, err := ec.EstimateGas(ctx, ec.walletMgr.GetAddress(), &router, value, data)
+    if err != nil {
+        return 0, fmt.Errorf("failed to estimate gas: %w", err)
+    }
+    return gasEstimate, nil
+}
+
+func v2SwapGasFallback(route *v2Route) uint64 {
+    return v2SwapGasBase + v2SwapGasPerHop*uint64(len(route.Path)-1)
+}
+
+// QuoterV2 返回的是池子内的 gas 消耗，再加上 router 本身的开销
+func v3SwapGasFallback(route *v3Route) uint64 {
+    total := uint64(v3SwapGasOverhead)
+    for _, q := range route.Quotes {
+        total += q.GasEstimate.Uint64()
+    }
+    return total
+}
@@ -0,0 +1,51 @@
+package ethereum
+
+import (
+    "context"
+    "math/big"
+    "testing"
+    "time"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestApproveToken_Validation(t *testing.T) {
+    ec := &EthereumClient{quotes: newQuoteStore(time.Minute)}
+    ctx := context.Background()
+
+    resp, err := ec.ApproveToken(ctx, &ApproveTokenRequest{QuoteID: "q", Mode: "infinite"})
+    assert.NoError(t, err)
+    assert.False(t, resp.Success)
+    assert.NotNil(t, resp.Error)
+
+    resp, err = ec.ApproveToken(ctx, &ApproveTokenRequest{QuoteID: "missing"})
+    assert.NoError(t, err)
+    assert.Equal(t, ApprovalModeExact, resp.Mode)
+    assert.NotNil(t, resp.Error)
+
+    // 授权已足够的报价不需要 approve
+    quote := ec.quotes.save(&SwapRequest{FromToken: "USDC", ToToken: "ETH"}, &SwapResponse{Success: true}, 100)
+    resp, err = ec.ApproveToken(ctx, &ApproveTokenRequest{QuoteID: quote.ID, Mode: ApprovalModeUnlimited})
+    assert.NoError(t, err)
+    assert.Equal(t, "quote does not require an approval", *resp.Error)
+}
+
+func TestEstimateSwapGas_ApprovalFallback(t *testing.T) {
+    // 授权不足时不调用 EstimateGas，直接返回经验值
+    ec := &EthereumClient{}
+    gas, err := ec.estimateSwapGas(context.Background(), common.Address{}, big.NewInt(0), nil, &TokenApproval{}, 210000)
+    assert.NoError(t, err)
+    assert.Equal(t, uint64(210000), gas)
+}
+
+func TestSwapGasFallback(t *testing.T) {
+    v2 := &v2Route{Path: []common.Address{{1}, {2}, {3}}}
+    assert.Equal(t, uint64(v2SwapGasBase+2*v2SwapGasPerHop), v2SwapGasFallback(v2))
+
+    v3 := &v3Route{Quotes: []*v3Quote{
+        {GasEstimate: big.NewInt(80000)},
+        {GasEstimate: big.NewInt(70000)},
+    }}
+    assert.Equal(t, uint64(v3SwapGasOverhead+150000), v3SwapGasFallback(v3))
+}
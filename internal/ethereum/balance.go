@@ -2,32 +2,44 @@
 package ethereum
 
 import (
+    "bytes"
     "context"
+    "errors"
     "fmt"
     "math/big"
+    "time"
 
-    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/accounts/abi"
     "github.com/ethereum/go-ethereum/accounts/abi/bind"
-    "go.uber.org/zap"
+    "github.com/ethereum/go-ethereum/common"
 
     "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
 )
 
-// ERC20 只包含 balanceOf
-const erc20BalanceOfABI = `[{"constant":true,"inputs":[{"name":"_owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"balance","type":"uint256"}],"type":"function"}]`
+// 地址上没有合约代码 (EOA 或未部署) 时 ERC20Caller 返回的错误
+var ErrNotContract = errors.New("no contract code at address")
 
 type BalanceResponse struct {
     Address      string          `json:"address"`
+    AddressName  *string         `json:"address_name,omitempty"`
     TokenAddress *string         `json:"token_address,omitempty"`
     Balance      decimal.Decimal `json:"balance"`
     Decimals     int             `json:"decimals"`
     Symbol       *string         `json:"symbol,omitempty"`
     Name         *string         `json:"name,omitempty"`
     IsETH        bool            `json:"is_eth"`
+    // 查询历史区块时返回实际使用的区块
+    BlockNumber    *uint64    `json:"block_number,omitempty"`
+    BlockTimestamp *time.Time `json:"block_timestamp,omitempty"`
 }
 
 func (ec *EthereumClient) GetBalance(ctx context.Context, addressStr string, tokenAddressStr *string) (*BalanceResponse, error) {
-    address, err := ec.ValidateAddress(addressStr)
+    return ec.GetBalanceAt(ctx, addressStr, tokenAddressStr, nil)
+}
+
+// 按区块号或时间戳查询历史余额，at 为 nil 时查询最新区块
+func (ec *EthereumClient) GetBalanceAt(ctx context.Context, addressStr string, tokenAddressStr *string, at *BlockSelector) (*BalanceResponse, error) {
+    address, err := ec.ResolveAddress(ctx, addressStr)
     if err != nil {
         return nil, err
     }
@@ -36,35 +48,45 @@ func (ec *EthereumClient) GetBalance(ctx context.Context, addressStr string, tok
     isETH := tokenAddressStr == nil
 
     if !isETH {
-        tokenAddress, err = ec.ValidateAddress(*tokenAddressStr)
+        tokenAddress, err = ec.ResolveAddress(ctx, *tokenAddressStr)
         if err != nil {
             return nil, err
         }
     }
 
+    header, err := ec.resolveBlock(ctx, at)
+    if err != nil {
+        return nil, err
+    }
+    var blockNumber *big.Int
+    if header != nil {
+        blockNumber = header.Number
+    }
+
+    var resp *BalanceResponse
     if isETH {
         // 查询 ETH余额
-        balance, err := ec.client.BalanceAt(ctx, address, nil)
+        balance, err := ec.client.BalanceAt(ctx, address, blockNumber)
         if err != nil {
-            return nil, fmt.Errorf("failed to get ETH balance: %w", err)
+            return nil, fmt.Errorf("failed to get ETH balance: %w", historicalStateError(err, blockNumber))
         }
 
-        return &BalanceResponse{
+        resp = &BalanceResponse{
             Address:  address.Hex(),
             Balance:  decimal.FromWei(balance),
             Decimals: 18,
             Symbol:   stringPtr("ETH"),
             Name:     stringPtr("Ethereum"),
             IsETH:    true,
-        }, nil
+        }
     } else {
         // 查询ERC20 代币余额
-        balance, decimals, symbol, name, err := ec.getERC20Balance(ctx, address, tokenAddress)
+        balance, decimals, symbol, name, err := ec.getERC20Balance(ctx, address, tokenAddress, blockNumber)
         if err != nil {
-            return nil, err
+            return nil, historicalStateError(err, blockNumber)
         }
 
-        return &BalanceResponse{
+        resp = &BalanceResponse{
             Address:      address.Hex(),
             TokenAddress: stringPtr(tokenAddress.Hex()),
             Balance:      decimal.FormatBalance(balance, decimals),
@@ -72,73 +94,260 @@ func (ec *EthereumClient) GetBalance(ctx context.Context, addressStr string, tok
             Symbol:       symbol,
             Name:         name,
             IsETH:        false,
-        }, nil
+        }
     }
+
+    resp.AddressName = ec.addressName(ctx, addressStr, address)
+    if header != nil {
+        number := header.Number.Uint64()
+        timestamp := time.Unix(int64(header.Time), 0).UTC()
+        resp.BlockNumber = &number
+        resp.BlockTimestamp = &timestamp
+    }
+    return resp, nil
+}
+
+// 非归档节点只保留最近的状态，历史查询失败时提示需要归档节点
+func historicalStateError(err error, blockNumber *big.Int) error {
+    if blockNumber == nil {
+        return err
+    }
+    return fmt.Errorf("at block %s (historical state may require an archive node): %w", blockNumber.String(), err)
 }
 
-func (ec *EthereumClient) getERC20Balance(ctx context.Context, address, tokenAddress common.Address) (*big.Int, int, *string, *string, error) {
+func (ec *EthereumClient) getERC20Balance(ctx context.Context, address, tokenAddress common.Address, blockNumber *big.Int) (*big.Int, int, *string, *string, error) {
     callOpts := &bind.CallOpts{
-        Context: ctx,
+        Context:     ctx,
+        BlockNumber: blockNumber,
     }
 
-    // 合约调用
-    token, err := NewERC20Caller(tokenAddress, ec.client)
+    infos, err := ec.erc20Infos(callOpts, address, []common.Address{tokenAddress})
     if err != nil {
-        return nil, 0, nil, nil, fmt.Errorf("failed to create token caller: %w", err)
+        return nil, 0, nil, nil, err
+    }
+    info := infos[0]
+    if info.Err != nil {
+        return nil, 0, nil, nil, fmt.Errorf("failed to get token balance: %w", info.Err)
     }
 
-    balance, err := token.BalanceOf(callOpts, address)
+    return info.Balance, info.Decimals, info.Symbol, info.Name, nil
+}
+
+// 单个代币的余额和元数据，Err 不为空时其他字段无效
+type erc20Info struct {
+    Token    common.Address
+    Balance  *big.Int
+    Decimals int
+    Symbol   *string
+    Name     *string
+    Err      error
+}
+
+// 每个代币依次读取 balanceOf、decimals、symbol、name
+const erc20InfoCalls = 4
+
+// 用一次 Multicall3 读取多个代币的余额和元数据，链上没有 Multicall3 时逐个调用
+func (ec *EthereumClient) erc20Infos(opts *bind.CallOpts, owner common.Address, tokens []common.Address) ([]erc20Info, error) {
+    balanceOfData, err := erc20ABI.Pack("balanceOf", owner)
     if err != nil {
-        return nil, 0, nil, nil, fmt.Errorf("failed to get token balance: %w", err)
+        return nil, fmt.Errorf("failed to build balanceOf data: %w", err)
     }
+    methodData := [][]byte{balanceOfData, erc20ABI.Methods["decimals"].ID, erc20ABI.Methods["symbol"].ID, erc20ABI.Methods["name"].ID}
 
-    decimals, err := token.Decimals(callOpts)
+    calls := make([]multicall3Call, 0, len(tokens)*erc20InfoCalls)
+    for _, token := range tokens {
+        for _, data := range methodData {
+            calls = append(calls, multicall3Call{Target: token, AllowFailure: true, CallData: data})
+        }
+    }
+
+    infos := make([]erc20Info, len(tokens))
+    results, err := ec.multicall.aggregate(opts.Context, opts.BlockNumber, calls)
+    if errors.Is(err, errMulticallUnavailable) {
+        ec.logger.Debug("Multicall3 not available, reading tokens one by one")
+        for i, token := range tokens {
+            infos[i] = ec.erc20InfoSequential(opts, owner, token)
+        }
+        return infos, nil
+    }
     if err != nil {
-        decimals = 18
+        return nil, err
     }
 
-    symbol, err := token.Symbol(callOpts)
-    var symbolPtr *string
-    if err == nil {
-        symbolPtr = &symbol
+    for i, token := range tokens {
+        infos[i] = ec.decodeERC20Info(token, results[i*erc20InfoCalls:(i+1)*erc20InfoCalls])
     }
+    return infos, nil
+}
 
-    name, err := token.Name(callOpts)
-    var namePtr *string
-    if err == nil {
-        namePtr = &name
+func (ec *EthereumClient) decodeERC20Info(token common.Address, results []multicall3Result) erc20Info {
+    info := erc20Info{Token: token}
+
+    // 调用没有代码的地址会成功但不返回数据
+    balanceResult := results[0]
+    if !balanceResult.Success {
+        info.Err = fmt.Errorf("failed to call balanceOf on %s: execution reverted", token.Hex())
+        return info
+    }
+    if len(balanceResult.ReturnData) == 0 {
+        info.Err = fmt.Errorf("%w: %s", ErrNotContract, token.Hex())
+        return info
+    }
+    out, err := erc20ABI.Unpack("balanceOf", balanceResult.ReturnData)
+    if err != nil {
+        info.Err = fmt.Errorf("failed to decode balanceOf of %s: %w", token.Hex(), err)
+        return info
+    }
+    info.Balance = abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+
+    // 没有 decimals 方法的代币按 18 位处理
+    info.Decimals = 18
+    if results[1].Success {
+        if out, err := erc20ABI.Unpack("decimals", results[1].ReturnData); err == nil {
+            info.Decimals = int(*abi.ConvertType(out[0], new(uint8)).(*uint8))
+            ec.decimalsMu.Lock()
+            ec.decimalsCache[token] = info.Decimals
+            ec.decimalsMu.Unlock()
+        }
     }
 
-    return balance, int(decimals), symbolPtr, namePtr, nil
+    if results[2].Success {
+        if symbol, err := unpackERC20String("symbol", results[2].ReturnData); err == nil {
+            info.Symbol = &symbol
+        }
+    }
+    if results[3].Success {
+        if name, err := unpackERC20String("name", results[3].ReturnData); err == nil {
+            info.Name = &name
+        }
+    }
+    return info
 }
 
-// ERC20
+func (ec *EthereumClient) erc20InfoSequential(opts *bind.CallOpts, owner, token common.Address) erc20Info {
+    info := erc20Info{Token: token}
+
+    caller := NewERC20Caller(token, ec.caller)
+
+    info.Balance, info.Err = caller.BalanceOf(opts, owner)
+    if info.Err != nil {
+        return info
+    }
+
+    decimals, err := caller.Decimals(opts)
+    if err != nil {
+        decimals = 18
+    }
+    info.Decimals = int(decimals)
+
+    if symbol, err := caller.Symbol(opts); err == nil {
+        info.Symbol = &symbol
+    }
+    if name, err := caller.Name(opts); err == nil {
+        info.Name = &name
+    }
+    return info
+}
+
+// name/symbol 先按 string 解码，失败时按 bytes32 解码
+func unpackERC20String(method string, data []byte) (string, error) {
+    if out, err := erc20ABI.Unpack(method, data); err == nil {
+        return *abi.ConvertType(out[0], new(string)).(*string), nil
+    }
+    out, err := erc20Bytes32ABI.Unpack(method, data)
+    if err != nil {
+        return "", err
+    }
+    value := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+    return string(bytes.TrimRight(value[:], "\x00")), nil
+}
+
+// 通过 eth_call 调用 ERC20 的只读方法
 type ERC20Caller struct {
+    address  common.Address
+    contract *bind.BoundContract
+    bytes32  *bind.BoundContract
+}
 
+func NewERC20Caller(address common.Address, caller bind.ContractCaller) *ERC20Caller {
+    return &ERC20Caller{
+        address:  address,
+        contract: bind.NewBoundContract(address, erc20ABI, caller, nil, nil),
+        bytes32:  bind.NewBoundContract(address, erc20Bytes32ABI, caller, nil, nil),
+    }
 }
 
-func NewERC20Caller(address common.Address, client *ethclient.Client) (*ERC20Caller, error) {
-    return &ERC20Caller{}, nil
+func (e *ERC20Caller) BalanceOf(opts *bind.CallOpts, owner common.Address) (*big.Int, error) {
+    out, err := e.call(opts, "balanceOf", owner)
+    if err != nil {
+        return nil, err
+    }
+    return abi.ConvertType(out[0], new(big.Int)).(*big.Int), nil
 }
 
-func (e *ERC20Caller) BalanceOf(opts *bind.CallOpts, address common.Address) (*big.Int, error) {
-    // 实现balanceOf 调用
-    return big.NewInt(0), nil
+func (e *ERC20Caller) TotalSupply(opts *bind.CallOpts) (*big.Int, error) {
+    out, err := e.call(opts, "totalSupply")
+    if err != nil {
+        return nil, err
+    }
+    return abi.ConvertType(out[0], new(big.Int)).(*big.Int), nil
 }
 
 func (e *ERC20Caller) Decimals(opts *bind.CallOpts) (uint8, error) {
-    // 实现 decimals调用
-    return 18, nil
+    out, err := e.call(opts, "decimals")
+    if err != nil {
+        return 0, err
+    }
+    return *abi.ConvertType(out[0], new(uint8)).(*uint8), nil
+}
+
+func (e *ERC20Caller) Allowance(opts *bind.CallOpts, owner, spender common.Address) (*big.Int, error) {
+    out, err := e.call(opts, "allowance", owner, spender)
+    if err != nil {
+        return nil, err
+    }
+    return abi.ConvertType(out[0], new(big.Int)).(*big.Int), nil
 }
 
 func (e *ERC20Caller) Symbol(opts *bind.CallOpts) (string, error) {
-    // 实现 symbol 调用
-    return "", nil
+    return e.callString(opts, "symbol")
 }
 
 func (e *ERC20Caller) Name(opts *bind.CallOpts) (string, error) {
-    // 实现 name 调用
-    return "", nil
+    return e.callString(opts, "name")
+}
+
+func (e *ERC20Caller) call(opts *bind.CallOpts, method string, args ...interface{}) ([]interface{}, error) {
+    var out []interface{}
+    if err := e.contract.Call(opts, &out, method, args...); err != nil {
+        return nil, e.wrapError(method, err)
+    }
+    return out, nil
+}
+
+// string 解码失败时按 bytes32 再解码一次
+func (e *ERC20Caller) callString(opts *bind.CallOpts, method string) (string, error) {
+    out, err := e.call(opts, method)
+    if err == nil {
+        return *abi.ConvertType(out[0], new(string)).(*string), nil
+    }
+    if errors.Is(err, ErrNotContract) {
+        return "", err
+    }
+
+    var raw []interface{}
+    if fallbackErr := e.bytes32.Call(opts, &raw, method); fallbackErr != nil {
+        return "", err
+    }
+    value := *abi.ConvertType(raw[0], new([32]byte)).(*[32]byte)
+    return string(bytes.TrimRight(value[:], "\x00")), nil
+}
+
+func (e *ERC20Caller) wrapError(method string, err error) error {
+    if errors.Is(err, bind.ErrNoCode) {
+        return fmt.Errorf("%w: %s", ErrNotContract, e.address.Hex())
+    }
+    return fmt.Errorf("failed to call %s on %s: %w", method, e.address.Hex(), err)
 }
 
 func stringPtr(s string) *string {
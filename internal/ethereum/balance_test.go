@@ -2,8 +2,16 @@ package ethereum_test
 
 import (
     "context"
+    "errors"
+    "math/big"
     "testing"
 
+    geth "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/accounts/abi"
+    "github.com/ethereum/go-ethereum/accounts/abi/bind"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/common/hexutil"
+    "github.com/ethereum/go-ethereum/crypto"
     "github.com/stretchr/testify/assert"
     "github.com/stretchr/testify/mock"
     "go.uber.org/zap"
@@ -87,3 +95,92 @@ func TestValidateAddress(t *testing.T) {
     _, err = ethClient.ValidateAddress(invalidAddr)
     assert.Error(t, err)
 }
+
+// 按方法选择器返回预设结果的合约调用模拟
+type fakeContractCaller struct {
+    code    []byte
+    results map[string][]byte
+}
+
+func (f *fakeContractCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+    return f.code, nil
+}
+
+func (f *fakeContractCaller) CallContract(ctx context.Context, call geth.CallMsg, blockNumber *big.Int) ([]byte, error) {
+    if len(f.code) == 0 {
+        return nil, nil
+    }
+    out, ok := f.results[hexutil.Encode(call.Data[:4])]
+    if !ok {
+        return nil, errors.New("execution reverted")
+    }
+    return out, nil
+}
+
+func selector(signature string) string {
+    return hexutil.Encode(crypto.Keccak256([]byte(signature))[:4])
+}
+
+func packResult(t *testing.T, typ string, value interface{}) []byte {
+    abiType, err := abi.NewType(typ, "", nil)
+    assert.NoError(t, err)
+    out, err := abi.Arguments{{Type: abiType}}.Pack(value)
+    assert.NoError(t, err)
+    return out
+}
+
+func TestERC20Caller(t *testing.T) {
+    var makerName [32]byte
+    copy(makerName[:], "Maker")
+
+    caller := &fakeContractCaller{
+        code: []byte{0x60, 0x80},
+        results: map[string][]byte{
+            selector("balanceOf(address)"):         packResult(t, "uint256", big.NewInt(1500000)),
+            selector("totalSupply()"):              packResult(t, "uint256", big.NewInt(1000000000)),
+            selector("decimals()"):                 packResult(t, "uint8", uint8(6)),
+            selector("symbol()"):                   packResult(t, "string", "USDC"),
+            selector("name()"):                     packResult(t, "bytes32", makerName),
+            selector("allowance(address,address)"): packResult(t, "uint256", big.NewInt(42)),
+        },
+    }
+    token := ethereum.NewERC20Caller(common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"), caller)
+    opts := &bind.CallOpts{Context: context.Background()}
+    owner := common.HexToAddress("0x742d35Cc6634C0532925a3b8D7a2a5c4A7A6A5a5")
+
+    balance, err := token.BalanceOf(opts, owner)
+    assert.NoError(t, err)
+    assert.Equal(t, big.NewInt(1500000), balance)
+
+    supply, err := token.TotalSupply(opts)
+    assert.NoError(t, err)
+    assert.Equal(t, big.NewInt(1000000000), supply)
+
+    decimals, err := token.Decimals(opts)
+    assert.NoError(t, err)
+    assert.Equal(t, uint8(6), decimals)
+
+    symbol, err := token.Symbol(opts)
+    assert.NoError(t, err)
+    assert.Equal(t, "USDC", symbol)
+
+    // bytes32 返回值的代币
+    name, err := token.Name(opts)
+    assert.NoError(t, err)
+    assert.Equal(t, "Maker", name)
+
+    allowance, err := token.Allowance(opts, owner, common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D"))
+    assert.NoError(t, err)
+    assert.Equal(t, big.NewInt(42), allowance)
+}
+
+func TestERC20Caller_NotContract(t *testing.T) {
+    token := ethereum.NewERC20Caller(common.HexToAddress("0x742d35Cc6634C0532925a3b8D7a2a5c4A7A6A5a5"), &fakeContractCaller{})
+    opts := &bind.CallOpts{Context: context.Background()}
+
+    _, err := token.BalanceOf(opts, common.HexToAddress("0x742d35Cc6634C0532925a3b8D7a2a5c4A7A6A5a5"))
+    assert.True(t, errors.Is(err, ethereum.ErrNotContract))
+
+    _, err = token.Symbol(opts)
+    assert.True(t, errors.Is(err, ethereum.ErrNotContract))
+}
@@ -0,0 +1,89 @@
+//历史区块定位
+package ethereum
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+    "time"
+
+    "github.com/ethereum/go-ethereum/core/types"
+)
+
+// 历史查询的区块，BlockNumber 和 Timestamp 只能指定一个，都为空时查询最新状态
+type BlockSelector struct {
+    BlockNumber *uint64
+    Timestamp   *time.Time
+}
+
+type headerSource interface {
+    HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// 把区块号或时间戳解析成具体区块，返回 nil 表示最新区块
+func (ec *EthereumClient) resolveBlock(ctx context.Context, at *BlockSelector) (*types.Header, error) {
+    if at == nil || (at.BlockNumber == nil && at.Timestamp == nil) {
+        return nil, nil
+    }
+    if at.BlockNumber != nil && at.Timestamp != nil {
+        return nil, fmt.Errorf("block_number and timestamp are mutually exclusive")
+    }
+
+    latest, err := ec.client.HeaderByNumber(ctx, nil)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get latest header: %w", err)
+    }
+
+    if at.BlockNumber != nil {
+        if *at.BlockNumber > latest.Number.Uint64() {
+            return nil, fmt.Errorf("block %d is after the latest block %d", *at.BlockNumber, latest.Number.Uint64())
+        }
+        header, err := ec.client.HeaderByNumber(ctx, new(big.Int).SetUint64(*at.BlockNumber))
+        if err != nil {
+            return nil, fmt.Errorf("failed to get header of block %d: %w", *at.BlockNumber, err)
+        }
+        return header, nil
+    }
+
+    return findBlockByTimestamp(ctx, ec.client, latest, *at.Timestamp)
+}
+
+// 二分查找时间戳不晚于 at 的最后一个区块，即该时刻链上的最新状态
+func findBlockByTimestamp(ctx context.Context, source headerSource, latest *types.Header, at time.Time) (*types.Header, error) {
+    // 1970 年之前的时间 Unix() 为负数，不能直接转换成 uint64
+    if at.Unix() < 0 {
+        return nil, fmt.Errorf("timestamp %s is before the genesis block", at.UTC().Format(time.RFC3339))
+    }
+    ts := uint64(at.Unix())
+    if ts > latest.Time {
+        return nil, fmt.Errorf("timestamp %s is after the latest block %d (%s)",
+            at.UTC().Format(time.RFC3339), latest.Number.Uint64(), time.Unix(int64(latest.Time), 0).UTC().Format(time.RFC3339))
+    }
+    if ts == latest.Time {
+        return latest, nil
+    }
+
+    genesis, err := source.HeaderByNumber(ctx, big.NewInt(0))
+    if err != nil {
+        return nil, fmt.Errorf("failed to get genesis header: %w", err)
+    }
+    if ts < genesis.Time {
+        return nil, fmt.Errorf("timestamp %s is before the genesis block", at.UTC().Format(time.RFC3339))
+    }
+
+    // 不变量: lo.Time <= ts < hi.Time
+    lo, hi := genesis, latest
+    for hi.Number.Uint64()-lo.Number.Uint64() > 1 {
+        mid := (lo.Number.Uint64() + hi.Number.Uint64()) / 2
+        header, err := source.HeaderByNumber(ctx, new(big.Int).SetUint64(mid))
+        if err != nil {
+            return nil, fmt.Errorf("failed to get header of block %d: %w", mid, err)
+        }
+        if header.Time <= ts {
+            lo = header
+        } else {
+            hi = header
+        }
+    }
+    return lo, nil
+}
@@ -0,0 +1,63 @@
+package ethereum
+
+import (
+    "context"
+    "math/big"
+    "testing"
+    "time"
+
+    "github.com/ethereum/go-ethereum/core/types"
+    "github.com/stretchr/testify/assert"
+)
+
+// 模拟链: 第 n 个区块的时间为 genesis + 12n
+type fakeHeaders struct {
+    genesis uint64
+    count   uint64
+    calls   int
+}
+
+func (f *fakeHeaders) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+    f.calls++
+    return f.header(number.Uint64()), nil
+}
+
+func (f *fakeHeaders) header(n uint64) *types.Header {
+    return &types.Header{Number: new(big.Int).SetUint64(n), Time: f.genesis + 12*n}
+}
+
+func TestFindBlockByTimestamp(t *testing.T) {
+    chain := &fakeHeaders{genesis: 1600000000, count: 1000000}
+    latest := chain.header(chain.count - 1)
+    ctx := context.Background()
+
+    // 正好是某个区块的时间
+    at := func(ts uint64) time.Time { return time.Unix(int64(ts), 0) }
+
+    header, err := findBlockByTimestamp(ctx, chain, latest, at(chain.genesis+12*4321))
+    assert.NoError(t, err)
+    assert.Equal(t, uint64(4321), header.Number.Uint64())
+
+    // 两个区块之间取前一个
+    header, err = findBlockByTimestamp(ctx, chain, latest, at(chain.genesis+12*777+5))
+    assert.NoError(t, err)
+    assert.Equal(t, uint64(777), header.Number.Uint64())
+
+    // 二分查找只需要对数次数的请求
+    assert.Less(t, chain.calls, 50)
+
+    header, err = findBlockByTimestamp(ctx, chain, latest, at(latest.Time))
+    assert.NoError(t, err)
+    assert.Equal(t, latest.Number, header.Number)
+
+    // 晚于最新区块的时间不静默返回最新区块
+    _, err = findBlockByTimestamp(ctx, chain, latest, at(latest.Time+3600))
+    assert.Error(t, err)
+
+    _, err = findBlockByTimestamp(ctx, chain, latest, at(chain.genesis-1))
+    assert.Error(t, err)
+
+    // 1970 年之前的时间
+    _, err = findBlockByTimestamp(ctx, chain, latest, time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC))
+    assert.Error(t, err)
+}
@@ -0,0 +1,88 @@
+//EIP-55 地址校验和检查
+package ethereum
+
+import (
+    "fmt"
+    "strings"
+
+    "github.com/ethereum/go-ethereum/common"
+    "go.uber.org/zap"
+)
+
+// 用于交易的地址的校验和检查级别
+const (
+    // 不检查校验和
+    ChecksumModeOff = "off"
+    // 校验和错误或缺失时只给出警告
+    ChecksumModeWarn = "warn"
+    // 大小写混合但校验和错误时拒绝，全小写或全大写时给出警告
+    ChecksumModeStrict = "strict"
+)
+
+func validChecksumMode(mode string) error {
+    switch mode {
+    case "", ChecksumModeOff, ChecksumModeWarn, ChecksumModeStrict:
+        return nil
+    }
+    return fmt.Errorf("address checksum mode must be %q, %q or %q", ChecksumModeOff, ChecksumModeWarn, ChecksumModeStrict)
+}
+
+// 检查十六进制地址的 EIP-55 校验和。全小写或全大写的地址不带校验和，返回 hasChecksum 为 false
+func verifyChecksum(address string) (hasChecksum bool, valid bool) {
+    hex := strings.TrimPrefix(strings.TrimPrefix(address, "0x"), "0X")
+    if hex == strings.ToLower(hex) || hex == strings.ToUpper(hex) {
+        return false, true
+    }
+    return true, "0x"+hex == common.HexToAddress(address).Hex()
+}
+
+// 检查会用于交易的地址，返回给调用方的警告；strict 模式下校验和错误时返回错误
+func (ec *EthereumClient) checkAddressChecksum(field, address string) (*string, error) {
+    mode := ec.config.AddressChecksum
+    if mode == "" || mode == ChecksumModeOff || !common.IsHexAddress(address) {
+        return nil, nil
+    }
+
+    hasChecksum, valid := verifyChecksum(address)
+    var warning string
+    switch {
+    case !valid && mode == ChecksumModeStrict:
+        return nil, fmt.Errorf("%s %s has an invalid EIP-55 checksum, check the address for typos", field, address)
+    case !valid:
+        warning = fmt.Sprintf("%s %s has an invalid EIP-55 checksum, check the address for typos", field, address)
+    case !hasChecksum:
+        warning = fmt.Sprintf("%s %s has no EIP-55 checksum, use the checksummed form %s to catch typos", field, address, common.HexToAddress(address).Hex())
+    default:
+        return nil, nil
+    }
+
+    ec.logger.Warn("Address checksum warning",
+        zap.String("field", field),
+        zap.String("address", address),
+    )
+    return &warning, nil
+}
+
+// swap 的代币和接收地址都会写入交易，代币符号和 ENS 名称解析出的地址不需要检查
+func (ec *EthereumClient) checkSwapChecksums(req *SwapRequest) ([]string, error) {
+    fields := []struct {
+        name  string
+        value string
+    }{
+        {"from_token", req.FromToken},
+        {"to_token", req.ToToken},
+        {"recipient", req.Recipient},
+    }
+
+    var warnings []string
+    for _, f := range fields {
+        warning, err := ec.checkAddressChecksum(f.name, f.value)
+        if err != nil {
+            return nil, err
+        }
+        if warning != nil {
+            warnings = append(warnings, *warning)
+        }
+    }
+    return warnings, nil
+}
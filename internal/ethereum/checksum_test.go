@@ -0,0 +1,53 @@
+package ethereum
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "go.uber.org/zap"
+)
+
+const (
+    checksummedAddress = "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045"
+    badChecksumAddress = "0xD8dA6BF26964aF9D7eEd9e03E53415D37aA96045"
+    lowercaseAddress   = "0xd8da6bf26964af9d7eed9e03e53415d37aa96045"
+)
+
+func TestVerifyChecksum(t *testing.T) {
+    hasChecksum, valid := verifyChecksum(checksummedAddress)
+    assert.True(t, hasChecksum)
+    assert.True(t, valid)
+
+    hasChecksum, valid = verifyChecksum(badChecksumAddress)
+    assert.True(t, hasChecksum)
+    assert.False(t, valid)
+
+    hasChecksum, valid = verifyChecksum(lowercaseAddress)
+    assert.False(t, hasChecksum)
+    assert.True(t, valid)
+}
+
+func TestCheckSwapChecksums(t *testing.T) {
+    newClient := func(mode string) *EthereumClient {
+        return &EthereumClient{logger: zap.NewNop(), config: &EthereumConfig{AddressChecksum: mode}}
+    }
+
+    // strict: 校验和错误时拒绝，全小写时警告，代币符号不检查
+    ec := newClient(ChecksumModeStrict)
+    _, err := ec.checkSwapChecksums(&SwapRequest{FromToken: "ETH", ToToken: "USDC", Recipient: badChecksumAddress})
+    assert.Error(t, err)
+
+    warnings, err := ec.checkSwapChecksums(&SwapRequest{FromToken: "ETH", ToToken: lowercaseAddress, Recipient: checksummedAddress})
+    assert.NoError(t, err)
+    assert.Len(t, warnings, 1)
+    assert.Contains(t, warnings[0], checksummedAddress)
+
+    // warn: 校验和错误时只给出警告
+    warnings, err = newClient(ChecksumModeWarn).checkSwapChecksums(&SwapRequest{FromToken: "ETH", ToToken: "USDC", Recipient: badChecksumAddress})
+    assert.NoError(t, err)
+    assert.Len(t, warnings, 1)
+
+    warnings, err = newClient(ChecksumModeOff).checkSwapChecksums(&SwapRequest{FromToken: "ETH", ToToken: "USDC", Recipient: badChecksumAddress})
+    assert.NoError(t, err)
+    assert.Empty(t, warnings)
+}
@@ -2,9 +2,16 @@ package ethereum
 
 import (
     "context"
+    "crypto/ecdsa"
+    "fmt"
     "math/big"
+    "strings"
+    "sync"
+    "time"
 
+    "github.com/ethereum/go-ethereum/accounts/abi/bind"
     "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/crypto"
     "github.com/ethereum/go-ethereum/ethclient"
     "go.uber.org/zap"
 
@@ -13,18 +20,80 @@ import (
 )
 
 type EthereumClient struct {
-    client       *ethclient.Client
-    walletMgr    *wallet.WalletManager
-    logger       *zap.Logger
-    config       *EthereumConfig
+    client    *ethclient.Client
+    walletMgr *wallet.WalletManager
+    logger    *zap.Logger
+    config    *EthereumConfig
+
+    // 只读合约调用，默认使用 client
+    caller bind.ContractCaller
+    // 广播交易和中继 watcher 的链上查询，默认使用 client
+    chain relayChain
+
+    // 客户端的生命周期，Close 时取消并等待后台的中继 watcher 退出
+    ctx      context.Context
+    cancel   context.CancelFunc
+    watchers sync.WaitGroup
+    // 中继 watcher 的轮询间隔，为 0 时使用 txStatusPollInterval
+    pollInterval time.Duration
+
+    // 代币精度不会变化，查询一次后缓存
+    decimalsMu    sync.RWMutex
+    decimalsCache map[common.Address]int
+
+    quotes *quoteStore
+    txs    *txTracker
+    // 未配置中继时为 nil，交易直接公开广播
+    relay *relayClient
+
+    multicall *multicaller
+    ens       *ensCache
+
+    nativePrice *nativePriceCache
+    prices      *coinGeckoClient
 }
 
 type EthereumConfig struct {
-    RPCEndpoint     string
-    ChainID         int64
-    UniswapV2Router string
-    UniswapV3Router string
-    WETHAddress     string
+    RPCEndpoint      string
+    ChainID          int64
+    UniswapV2Router  string
+    UniswapV2Factory string
+    UniswapV3Router  string
+    UniswapV3Quoter  string
+    UniswapV3Factory string
+    WETHAddress      string
+    BaseTokens       []string
+
+    // 价格影响阈值(百分比)，超过 warn 提示，超过 block 拒绝，0 表示不启用
+    PriceImpactWarnPercent  float64
+    PriceImpactBlockPercent float64
+
+    // swap 报价的有效期
+    QuoteTTL time.Duration
+
+    // 私有中继地址，为空时公开广播；RelayFallbackBlocks 个区块内未上链则改为公开广播
+    RelayURL            string
+    RelayMode           string
+    RelayAuthKey        string
+    RelayFallbackBlocks uint64
+
+    // 为空时使用 Multicall3 的标准部署地址
+    Multicall3Address string
+
+    // 代币不支持 EIP-2612 时通过 Universal Router 使用 Permit2 签名授权，UniversalRouter 为空时不启用；
+    // Permit2Address 为空时使用标准部署地址
+    UniversalRouter string
+    Permit2Address  string
+
+    // get_portfolio 未指定代币时查询的代币符号或地址
+    Watchlist []string
+
+    // ENS registry 地址为空时使用标准部署地址
+    ENSRegistry string
+    ENSCacheTTL time.Duration
+
+    // swap 代币和接收地址的 EIP-55 校验和检查级别: off、warn 或 strict
+    AddressChecksum string
 }
 
 func NewEthereumClient(cfg *EthereumConfig, walletMgr *wallet.WalletManager, logger *zap.Logger) (*EthereumClient, error) {
@@ -33,11 +102,43 @@ func NewEthereumClient(cfg *EthereumConfig, walletMgr *wallet.WalletManager, log
         return nil, err
     }
 
+    if err := validChecksumMode(cfg.AddressChecksum); err != nil {
+        return nil, err
+    }
+
+    var relay *relayClient
+    if cfg.RelayURL != "" {
+        var authKey *ecdsa.PrivateKey
+        if cfg.RelayAuthKey != "" {
+            authKey, err = crypto.HexToECDSA(strings.TrimPrefix(cfg.RelayAuthKey, "0x"))
+            if err != nil {
+                return nil, fmt.Errorf("invalid relay auth key: %w", err)
+            }
+        }
+        relay, err = newRelayClient(cfg.RelayURL, cfg.RelayMode, authKey)
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
     return &EthereumClient{
-        client:    client,
-        walletMgr: walletMgr,
-        logger:    logger,
-        config:    cfg,
+        client:        client,
+        caller:        client,
+        chain:         client,
+        ctx:           ctx,
+        cancel:        cancel,
+        walletMgr:     walletMgr,
+        logger:        logger,
+        config:        cfg,
+        decimalsCache: make(map[common.Address]int),
+        quotes:        newQuoteStore(cfg.QuoteTTL),
+        txs:           newTxTracker(),
+        relay:         relay,
+        multicall:     newMulticaller(client, cfg.Multicall3Address),
+        ens:           newENSCache(cfg.ENSCacheTTL),
+        nativePrice:   newNativePriceCache(),
+        prices:        newCoinGeckoClient(),
     }, nil
 }
 
@@ -50,7 +151,7 @@ func (ec *EthereumClient) GetWalletManager() *wallet.WalletManager {
 }
 
 func (ec *EthereumClient) GetChainID() *big.Int {
-    return ec.walletMgr.ChainID
+    return ec.walletMgr.GetChainID()
 }
 
 func (ec *EthereumClient) ValidateAddress(address string) (common.Address, error) {
@@ -75,6 +176,10 @@ func (ec *EthereumClient) EstimateGas(ctx context.Context, from common.Address,
 }
 
 func (ec *EthereumClient) Close() {
+    if ec.cancel != nil {
+        ec.cancel()
+    }
+    ec.watchers.Wait()
     if ec.client != nil {
         ec.client.Close()
     }
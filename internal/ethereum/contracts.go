@@ -0,0 +1,220 @@
+//合约 ABI 定义
+package ethereum
+
+import (
+    "math/big"
+    "strings"
+
+    "github.com/ethereum/go-ethereum/accounts/abi"
+    "github.com/ethereum/go-ethereum/common"
+)
+
+// Uniswap V2 Router02 (只包含用到的方法)
+const uniswapV2RouterABIJSON = `[
+    {"inputs":[{"name":"amountOutMin","type":"uint256"},{"name":"path","type":"address[]"},{"name":"to","type":"address"},{"name":"deadline","type":"uint256"}],"name":"swapExactETHForTokens","outputs":[{"name":"amounts","type":"uint256[]"}],"stateMutability":"payable","type":"function"},
+    {"inputs":[{"name":"amountIn","type":"uint256"},{"name":"amountOutMin","type":"uint256"},{"name":"path","type":"address[]"},{"name":"to","type":"address"},{"name":"deadline","type":"uint256"}],"name":"swapExactTokensForETH","outputs":[{"name":"amounts","type":"uint256[]"}],"stateMutability":"nonpayable","type":"function"},
+    {"inputs":[{"name":"amountIn","type":"uint256"},{"name":"amountOutMin","type":"uint256"},{"name":"path","type":"address[]"},{"name":"to","type":"address"},{"name":"deadline","type":"uint256"}],"name":"swapExactTokensForTokens","outputs":[{"name":"amounts","type":"uint256[]"}],"stateMutability":"nonpayable","type":"function"},
+    {"inputs":[{"name":"amountOut","type":"uint256"},{"name":"path","type":"address[]"},{"name":"to","type":"address"},{"name":"deadline","type":"uint256"}],"name":"swapETHForExactTokens","outputs":[{"name":"amounts","type":"uint256[]"}],"stateMutability":"payable","type":"function"},
+    {"inputs":[{"name":"amountOut","type":"uint256"},{"name":"amountInMax","type":"uint256"},{"name":"path","type":"address[]"},{"name":"to","type":"address"},{"name":"deadline","type":"uint256"}],"name":"swapTokensForExactETH","outputs":[{"name":"amounts","type":"uint256[]"}],"stateMutability":"nonpayable","type":"function"},
+    {"inputs":[{"name":"amountOut","type":"uint256"},{"name":"amountInMax","type":"uint256"},{"name":"path","type":"address[]"},{"name":"to","type":"address"},{"name":"deadline","type":"uint256"}],"name":"swapTokensForExactTokens","outputs":[{"name":"amounts","type":"uint256[]"}],"stateMutability":"nonpayable","type":"function"},
+    {"inputs":[{"name":"amountIn","type":"uint256"},{"name":"path","type":"address[]"}],"name":"getAmountsOut","outputs":[{"name":"amounts","type":"uint256[]"}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"name":"amountOut","type":"uint256"},{"name":"path","type":"address[]"}],"name":"getAmountsIn","outputs":[{"name":"amounts","type":"uint256[]"}],"stateMutability":"view","type":"function"}
+]`
+
+// Uniswap V2 Factory
+const uniswapV2FactoryABIJSON = `[
+    {"inputs":[{"name":"tokenA","type":"address"},{"name":"tokenB","type":"address"}],"name":"getPair","outputs":[{"name":"pair","type":"address"}],"stateMutability":"view","type":"function"}
+]`
+
+// Uniswap V2 Pair
+const uniswapV2PairABIJSON = `[
+    {"inputs":[],"name":"getReserves","outputs":[{"name":"reserve0","type":"uint112"},{"name":"reserve1","type":"uint112"},{"name":"blockTimestampLast","type":"uint32"}],"stateMutability":"view","type":"function"}
+]`
+
+// Uniswap V3 SwapRouter
+const uniswapV3RouterABIJSON = `[
+    {"inputs":[{"components":[{"name":"tokenIn","type":"address"},{"name":"tokenOut","type":"address"},{"name":"fee","type":"uint24"},{"name":"recipient","type":"address"},{"name":"deadline","type":"uint256"},{"name":"amountIn","type":"uint256"},{"name":"amountOutMinimum","type":"uint256"},{"name":"sqrtPriceLimitX96","type":"uint160"}],"name":"params","type":"tuple"}],"name":"exactInputSingle","outputs":[{"name":"amountOut","type":"uint256"}],"stateMutability":"payable","type":"function"},
+    {"inputs":[{"components":[{"name":"path","type":"bytes"},{"name":"recipient","type":"address"},{"name":"deadline","type":"uint256"},{"name":"amountIn","type":"uint256"},{"name":"amountOutMinimum","type":"uint256"}],"name":"params","type":"tuple"}],"name":"exactInput","outputs":[{"name":"amountOut","type":"uint256"}],"stateMutability":"payable","type":"function"},
+    {"inputs":[{"components":[{"name":"tokenIn","type":"address"},{"name":"tokenOut","type":"address"},{"name":"fee","type":"uint24"},{"name":"recipient","type":"address"},{"name":"deadline","type":"uint256"},{"name":"amountOut","type":"uint256"},{"name":"amountInMaximum","type":"uint256"},{"name":"sqrtPriceLimitX96","type":"uint160"}],"name":"params","type":"tuple"}],"name":"exactOutputSingle","outputs":[{"name":"amountIn","type":"uint256"}],"stateMutability":"payable","type":"function"},
+    {"inputs":[],"name":"refundETH","outputs":[],"stateMutability":"payable","type":"function"},
+    {"inputs":[{"name":"amountMinimum","type":"uint256"},{"name":"recipient","type":"address"}],"name":"unwrapWETH9","outputs":[],"stateMutability":"payable","type":"function"},
+    {"inputs":[{"name":"token","type":"address"},{"name":"value","type":"uint256"},{"name":"deadline","type":"uint256"},{"name":"v","type":"uint8"},{"name":"r","type":"bytes32"},{"name":"s","type":"bytes32"}],"name":"selfPermit","outputs":[],"stateMutability":"payable","type":"function"},
+    {"inputs":[{"name":"data","type":"bytes[]"}],"name":"multicall","outputs":[{"name":"results","type":"bytes[]"}],"stateMutability":"payable","type":"function"}
+]`
+
+// Uniswap V3 QuoterV2
+const uniswapV3QuoterABIJSON = `[
+    {"inputs":[{"components":[{"name":"tokenIn","type":"address"},{"name":"tokenOut","type":"address"},{"name":"amountIn","type":"uint256"},{"name":"fee","type":"uint24"},{"name":"sqrtPriceLimitX96","type":"uint160"}],"name":"params","type":"tuple"}],"name":"quoteExactInputSingle","outputs":[{"name":"amountOut","type":"uint256"},{"name":"sqrtPriceX96After","type":"uint160"},{"name":"initializedTicksCrossed","type":"uint32"},{"name":"gasEstimate","type":"uint256"}],"stateMutability":"nonpayable","type":"function"},
+    {"inputs":[{"components":[{"name":"tokenIn","type":"address"},{"name":"tokenOut","type":"address"},{"name":"amount","type":"uint256"},{"name":"fee","type":"uint24"},{"name":"sqrtPriceLimitX96","type":"uint160"}],"name":"params","type":"tuple"}],"name":"quoteExactOutputSingle","outputs":[{"name":"amountIn","type":"uint256"},{"name":"sqrtPriceX96After","type":"uint160"},{"name":"initializedTicksCrossed","type":"uint32"},{"name":"gasEstimate","type":"uint256"}],"stateMutability":"nonpayable","type":"function"}
+]`
+
+// ERC20
+const erc20ABIJSON = `[
+    {"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+    {"inputs":[],"name":"totalSupply","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+    {"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"stateMutability":"view","type":"function"},
+    {"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"}],"name":"allowance","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}],"name":"approve","outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"},
+    {"inputs":[],"name":"name","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+    {"inputs":[],"name":"version","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"name":"owner","type":"address"}],"name":"nonces","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+    {"inputs":[],"name":"DOMAIN_SEPARATOR","outputs":[{"name":"","type":"bytes32"}],"stateMutability":"view","type":"function"}
+]`
+
+// 早期代币 (如 MKR) 的 name/symbol 返回 bytes32
+const erc20Bytes32ABIJSON = `[
+    {"inputs":[],"name":"name","outputs":[{"name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},
+    {"inputs":[],"name":"symbol","outputs":[{"name":"","type":"bytes32"}],"stateMutability":"view","type":"function"}
+]`
+
+// Uniswap V3 Factory
+const uniswapV3FactoryABIJSON = `[
+    {"inputs":[{"name":"tokenA","type":"address"},{"name":"tokenB","type":"address"},{"name":"fee","type":"uint24"}],"name":"getPool","outputs":[{"name":"pool","type":"address"}],"stateMutability":"view","type":"function"}
+]`
+
+// Uniswap V3 Pool
+const uniswapV3PoolABIJSON = `[
+    {"inputs":[],"name":"slot0","outputs":[{"name":"sqrtPriceX96","type":"uint160"},{"name":"tick","type":"int24"},{"name":"observationIndex","type":"uint16"},{"name":"observationCardinality","type":"uint16"},{"name":"observationCardinalityNext","type":"uint16"},{"name":"feeProtocol","type":"uint8"},{"name":"unlocked","type":"bool"}],"stateMutability":"view","type":"function"}
+]`
+
+// Multicall3 (只包含 aggregate3)
+const multicall3ABIJSON = `[
+    {"inputs":[{"components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}],"name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}],"name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}
+]`
+
+// ENS registry
+const ensRegistryABIJSON = `[
+    {"inputs":[{"name":"node","type":"bytes32"}],"name":"resolver","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"}
+]`
+
+// ENS public resolver (addr 和 name)
+const ensResolverABIJSON = `[
+    {"inputs":[{"name":"node","type":"bytes32"}],"name":"addr","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"name":"node","type":"bytes32"}],"name":"name","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"}
+]`
+
+// Uniswap Permit2 (只包含 allowance)
+const permit2ABIJSON = `[
+    {"inputs":[{"name":"user","type":"address"},{"name":"token","type":"address"},{"name":"spender","type":"address"}],"name":"allowance","outputs":[{"name":"amount","type":"uint160"},{"name":"expiration","type":"uint48"},{"name":"nonce","type":"uint48"}],"stateMutability":"view","type":"function"}
+]`
+
+// Uniswap Universal Router
+const universalRouterABIJSON = `[
+    {"inputs":[{"name":"commands","type":"bytes"},{"name":"inputs","type":"bytes[]"},{"name":"deadline","type":"uint256"}],"name":"execute","outputs":[],"stateMutability":"payable","type":"function"}
+]`
+
+// Universal Router 各命令的输入编码，不是合约方法，只用其 Inputs 打包
+const universalRouterInputsABIJSON = `[
+    {"inputs":[{"components":[{"components":[{"name":"token","type":"address"},{"name":"amount","type":"uint160"},{"name":"expiration","type":"uint48"},{"name":"nonce","type":"uint48"}],"name":"details","type":"tuple"},{"name":"spender","type":"address"},{"name":"sigDeadline","type":"uint256"}],"name":"permitSingle","type":"tuple"},{"name":"signature","type":"bytes"}],"name":"permit2Permit","outputs":[],"type":"function"},
+    {"inputs":[{"name":"recipient","type":"address"},{"name":"amountIn","type":"uint256"},{"name":"amountOutMin","type":"uint256"},{"name":"path","type":"bytes"},{"name":"payerIsUser","type":"bool"}],"name":"v3SwapExactIn","outputs":[],"type":"function"},
+    {"inputs":[{"name":"recipient","type":"address"},{"name":"amountOut","type":"uint256"},{"name":"amountInMax","type":"uint256"},{"name":"path","type":"bytes"},{"name":"payerIsUser","type":"bool"}],"name":"v3SwapExactOut","outputs":[],"type":"function"},
+    {"inputs":[{"name":"recipient","type":"address"},{"name":"amountIn","type":"uint256"},{"name":"amountOutMin","type":"uint256"},{"name":"path","type":"address[]"},{"name":"payerIsUser","type":"bool"}],"name":"v2SwapExactIn","outputs":[],"type":"function"},
+    {"inputs":[{"name":"recipient","type":"address"},{"name":"amountOut","type":"uint256"},{"name":"amountInMax","type":"uint256"},{"name":"path","type":"address[]"},{"name":"payerIsUser","type":"bool"}],"name":"v2SwapExactOut","outputs":[],"type":"function"},
+    {"inputs":[{"name":"recipient","type":"address"},{"name":"amountMin","type":"uint256"}],"name":"unwrapWETH","outputs":[],"type":"function"}
+]`
+
+var (
+    erc20ABI                 = mustParseABI(erc20ABIJSON)
+    erc20Bytes32ABI          = mustParseABI(erc20Bytes32ABIJSON)
+    uniswapV2RouterABI       = mustParseABI(uniswapV2RouterABIJSON)
+    uniswapV2FactoryABI      = mustParseABI(uniswapV2FactoryABIJSON)
+    uniswapV2PairABI         = mustParseABI(uniswapV2PairABIJSON)
+    uniswapV3RouterABI       = mustParseABI(uniswapV3RouterABIJSON)
+    uniswapV3QuoterABI       = mustParseABI(uniswapV3QuoterABIJSON)
+    uniswapV3FactoryABI      = mustParseABI(uniswapV3FactoryABIJSON)
+    uniswapV3PoolABI         = mustParseABI(uniswapV3PoolABIJSON)
+    multicall3ABI            = mustParseABI(multicall3ABIJSON)
+    ensRegistryABI           = mustParseABI(ensRegistryABIJSON)
+    ensResolverABI           = mustParseABI(ensResolverABIJSON)
+    permit2ABI               = mustParseABI(permit2ABIJSON)
+    universalRouterABI       = mustParseABI(universalRouterABIJSON)
+    universalRouterInputsABI = mustParseABI(universalRouterInputsABIJSON)
+)
+
+// QuoterV2.quoteExactInputSingle 的参数
+type v3QuoteExactInputSingleParams struct {
+    TokenIn           common.Address
+    TokenOut          common.Address
+    AmountIn          *big.Int
+    Fee               *big.Int
+    SqrtPriceLimitX96 *big.Int
+}
+
+// QuoterV2.quoteExactOutputSingle 的参数
+type v3QuoteExactOutputSingleParams struct {
+    TokenIn           common.Address
+    TokenOut          common.Address
+    Amount            *big.Int
+    Fee               *big.Int
+    SqrtPriceLimitX96 *big.Int
+}
+
+// SwapRouter.exactInputSingle 的参数
+type v3ExactInputSingleParams struct {
+    TokenIn           common.Address
+    TokenOut          common.Address
+    Fee               *big.Int
+    Recipient         common.Address
+    Deadline          *big.Int
+    AmountIn          *big.Int
+    AmountOutMinimum  *big.Int
+    SqrtPriceLimitX96 *big.Int
+}
+
+func mustParseABI(definition string) abi.ABI {
+    parsed, err := abi.JSON(strings.NewReader(definition))
+    if err != nil {
+        panic(err)
+    }
+    return parsed
+}
+
+// SwapRouter.exactInput 的参数
+type v3ExactInputParams struct {
+    Path             []byte
+    Recipient        common.Address
+    Deadline         *big.Int
+    AmountIn         *big.Int
+    AmountOutMinimum *big.Int
+}
+
+// SwapRouter.exactOutputSingle 的参数
+type v3ExactOutputSingleParams struct {
+    TokenIn           common.Address
+    TokenOut          common.Address
+    Fee               *big.Int
+    Recipient         common.Address
+    Deadline          *big.Int
+    AmountOut         *big.Int
+    AmountInMaximum   *big.Int
+    SqrtPriceLimitX96 *big.Int
+}
+
+// Multicall3.aggregate3 的单个调用
+type multicall3Call struct {
+    Target       common.Address
+    AllowFailure bool
+    CallData     []byte
+}
+
+// Multicall3.aggregate3 的单个返回值
+type multicall3Result struct {
+    Success    bool
+    ReturnData []byte
+}
+
+// Permit2 PermitSingle 中的 PermitDetails
+type permit2Details struct {
+    Token      common.Address
+    Amount     *big.Int
+    Expiration *big.Int
+    Nonce      *big.Int
+}
+
+// Universal Router PERMIT2_PERMIT 命令的 PermitSingle
+type permit2PermitSingle struct {
+    Details     permit2Details
+    Spender     common.Address
+    SigDeadline *big.Int
+}
@@ -0,0 +1,233 @@
+//ENS 正向和反向解析
+package ethereum
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/ethereum/go-ethereum/accounts/abi"
+    "github.com/ethereum/go-ethereum/accounts/abi/bind"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/crypto"
+    "go.uber.org/zap"
+)
+
+// ENS registry 在主网和测试网上部署在同一地址
+const defaultENSRegistryAddress = "0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e"
+
+const defaultENSCacheTTL = 5 * time.Minute
+
+// ENS 名称按 namehash 递归计算节点: node = keccak256(parent || keccak256(label))
+func namehash(name string) common.Hash {
+    var node common.Hash
+    if name == "" {
+        return node
+    }
+    labels := strings.Split(name, ".")
+    for i := len(labels) - 1; i >= 0; i-- {
+        labelHash := crypto.Keccak256([]byte(labels[i]))
+        node = crypto.Keccak256Hash(node.Bytes(), labelHash)
+    }
+    return node
+}
+
+// 带点号且每段都不为空的名称当作 ENS 名称，包括 DNS 导入的名称 (如 foo.xyz)。
+// 已知的代币符号除外；顶级域至少两个字符，USDC.e、BTC.b 这类桥接代币符号仍按符号查找
+func isENSName(s string) bool {
+    name := normalizeENSName(s)
+    if isKnownTokenSymbol(name) {
+        return false
+    }
+    labels := strings.Split(name, ".")
+    if len(labels) < 2 {
+        return false
+    }
+    for _, label := range labels {
+        if label == "" {
+            return false
+        }
+    }
+    return len(labels[len(labels)-1]) >= 2
+}
+
+// 只做大小写和首尾空白的规范化，完整的 ENSIP-15 规范化不在这里处理
+func normalizeENSName(name string) string {
+    return strings.ToLower(strings.TrimSpace(name))
+}
+
+type ensCacheEntry struct {
+    address common.Address
+    name    string
+    // 解析结果为空也缓存，避免重复查询没有设置记录的名称或地址
+    found   bool
+    expires time.Time
+}
+
+type ensCache struct {
+    mu      sync.Mutex
+    ttl     time.Duration
+    forward map[string]ensCacheEntry
+    reverse map[common.Address]ensCacheEntry
+}
+
+func newENSCache(ttl time.Duration) *ensCache {
+    if ttl <= 0 {
+        ttl = defaultENSCacheTTL
+    }
+    return &ensCache{
+        ttl:     ttl,
+        forward: make(map[string]ensCacheEntry),
+        reverse: make(map[common.Address]ensCacheEntry),
+    }
+}
+
+func (c *ensCache) getForward(name string) (ensCacheEntry, bool) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    entry, ok := c.forward[name]
+    if !ok || time.Now().After(entry.expires) {
+        return ensCacheEntry{}, false
+    }
+    return entry, true
+}
+
+func (c *ensCache) getReverse(address common.Address) (ensCacheEntry, bool) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    entry, ok := c.reverse[address]
+    if !ok || time.Now().After(entry.expires) {
+        return ensCacheEntry{}, false
+    }
+    return entry, true
+}
+
+func (c *ensCache) setForward(name string, address common.Address, found bool) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.forward[name] = ensCacheEntry{address: address, name: name, found: found, expires: time.Now().Add(c.ttl)}
+}
+
+func (c *ensCache) setReverse(address common.Address, name string, found bool) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.reverse[address] = ensCacheEntry{address: address, name: name, found: found, expires: time.Now().Add(c.ttl)}
+}
+
+// 接受十六进制地址或 ENS 名称
+func (ec *EthereumClient) ResolveAddress(ctx context.Context, s string) (common.Address, error) {
+    if !isENSName(s) {
+        return ec.ValidateAddress(s)
+    }
+    return ec.resolveENSName(ctx, s)
+}
+
+// registry.resolver(node) -> resolver.addr(node)
+func (ec *EthereumClient) resolveENSName(ctx context.Context, name string) (common.Address, error) {
+    name = normalizeENSName(name)
+    if entry, ok := ec.ens.getForward(name); ok {
+        if !entry.found {
+            return common.Address{}, fmt.Errorf("ENS name %s does not resolve to an address", name)
+        }
+        return entry.address, nil
+    }
+
+    node := namehash(name)
+    resolver, err := ec.ensResolver(ctx, node)
+    if err != nil {
+        return common.Address{}, fmt.Errorf("failed to resolve ENS name %s: %w", name, err)
+    }
+
+    var address common.Address
+    if resolver != (common.Address{}) {
+        contract := bind.NewBoundContract(resolver, ensResolverABI, ec.caller, nil, nil)
+        var out []interface{}
+        if err := contract.Call(&bind.CallOpts{Context: ctx}, &out, "addr", node); err != nil {
+            return common.Address{}, fmt.Errorf("failed to resolve ENS name %s: %w", name, err)
+        }
+        address = *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+    }
+
+    found := address != (common.Address{})
+    ec.ens.setForward(name, address, found)
+    if !found {
+        return common.Address{}, fmt.Errorf("ENS name %s does not resolve to an address", name)
+    }
+    return address, nil
+}
+
+// 反向解析地址的主名称，并确认该名称正向解析回同一地址；没有名称时返回空字符串
+func (ec *EthereumClient) LookupENSName(ctx context.Context, address common.Address) (string, error) {
+    if entry, ok := ec.ens.getReverse(address); ok {
+        return entry.name, nil
+    }
+
+    reverseName := strings.ToLower(strings.TrimPrefix(address.Hex(), "0x")) + ".addr.reverse"
+    node := namehash(reverseName)
+    resolver, err := ec.ensResolver(ctx, node)
+    if err != nil {
+        return "", fmt.Errorf("failed to look up ENS name of %s: %w", address.Hex(), err)
+    }
+
+    var name string
+    if resolver != (common.Address{}) {
+        contract := bind.NewBoundContract(resolver, ensResolverABI, ec.caller, nil, nil)
+        var out []interface{}
+        if err := contract.Call(&bind.CallOpts{Context: ctx}, &out, "name", node); err != nil {
+            return "", fmt.Errorf("failed to look up ENS name of %s: %w", address.Hex(), err)
+        }
+        name = *abi.ConvertType(out[0], new(string)).(*string)
+    }
+
+    // 反向记录可以由地址所有者任意设置，只有正向解析一致时才采用
+    if name != "" {
+        forward, err := ec.resolveENSName(ctx, name)
+        if err != nil || forward != address {
+            name = ""
+        }
+    }
+
+    ec.ens.setReverse(address, name, name != "")
+    return name, nil
+}
+
+// 调用方传入的是 ENS 名称时直接使用，否则反向解析
+func (ec *EthereumClient) addressName(ctx context.Context, input string, address common.Address) *string {
+    if isENSName(input) {
+        name := normalizeENSName(input)
+        return &name
+    }
+    return ec.ensNameFor(ctx, address)
+}
+
+// 给响应展示用的名称，查询失败时不影响主流程
+func (ec *EthereumClient) ensNameFor(ctx context.Context, address common.Address) *string {
+    name, err := ec.LookupENSName(ctx, address)
+    if err != nil {
+        ec.logger.Debug("ENS reverse lookup failed",
+            zap.String("address", address.Hex()),
+            zap.Error(err),
+        )
+        return nil
+    }
+    if name == "" {
+        return nil
+    }
+    return &name
+}
+
+func (ec *EthereumClient) ensResolver(ctx context.Context, node common.Hash) (common.Address, error) {
+    registryAddress := ec.config.ENSRegistry
+    if registryAddress == "" {
+        registryAddress = defaultENSRegistryAddress
+    }
+    registry := bind.NewBoundContract(common.HexToAddress(registryAddress), ensRegistryABI, ec.caller, nil, nil)
+
+    var out []interface{}
+    if err := registry.Call(&bind.CallOpts{Context: ctx}, &out, "resolver", node); err != nil {
+        return common.Address{}, fmt.Errorf("failed to get resolver: %w", err)
+    }
+    return *abi.ConvertType(out[0], new(common.Address)).(*common.Address), nil
+}
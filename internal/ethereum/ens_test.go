@@ -0,0 +1,112 @@
+package ethereum
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/stretchr/testify/assert"
+    "go.uber.org/zap"
+)
+
+func TestNamehash(t *testing.T) {
+    assert.Equal(t, common.Hash{}, namehash(""))
+    assert.Equal(t, common.HexToHash("0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"), namehash("eth"))
+    assert.Equal(t, common.HexToHash("0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"), namehash("foo.eth"))
+}
+
+func TestIsENSName(t *testing.T) {
+    assert.True(t, isENSName("vitalik.eth"))
+    assert.True(t, isENSName("sub.name.eth"))
+    assert.False(t, isENSName("0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045"))
+    assert.False(t, isENSName("USDC"))
+    assert.False(t, isENSName(""))
+    assert.False(t, isENSName(".eth"))
+    assert.False(t, isENSName("foo..eth"))
+    assert.False(t, isENSName("foo."))
+    // 桥接代币的符号
+    assert.False(t, isENSName("USDC.e"))
+    assert.False(t, isENSName("BTC.b"))
+    assert.True(t, isENSName(" Vitalik.ETH "))
+    // DNS 导入的名称
+    assert.True(t, isENSName("foo.xyz"))
+    assert.True(t, isENSName("pay.example.com"))
+}
+
+func TestGetTokenPrice_ENS(t *testing.T) {
+    registry := common.HexToAddress(defaultENSRegistryAddress)
+    resolver := common.HexToAddress("0x231b0Ee14048e9dCcD1d247744d114a4EB5E8E63")
+    token := common.HexToAddress("0x1111111111111111111111111111111111111111")
+    node := namehash("token.example.xyz")
+
+    caller := fakeContracts{
+        registry: {abi: ensRegistryABI, methods: map[string]fakeMethod{
+            "resolver": func(args []interface{}) ([]interface{}, error) {
+                return []interface{}{resolver}, nil
+            },
+        }},
+        resolver: {abi: ensResolverABI, methods: map[string]fakeMethod{
+            "addr": func(args []interface{}) ([]interface{}, error) {
+                if common.Hash(args[0].([32]byte)) != node {
+                    return []interface{}{common.Address{}}, nil
+                }
+                return []interface{}{token}, nil
+            },
+        }},
+    }
+
+    var paths []string
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        paths = append(paths, r.URL.Path)
+        fmt.Fprintf(w, `{"%s":{"usd":2.5,"eth":0.001}}`, strings.ToLower(token.Hex()))
+    }))
+    defer server.Close()
+    prices := newCoinGeckoClient()
+    prices.baseURL = server.URL
+
+    ec := &EthereumClient{
+        caller: caller,
+        logger: zap.NewNop(),
+        config: &EthereumConfig{ChainID: 1},
+        ens:    newENSCache(time.Minute),
+        prices: prices,
+    }
+
+    // 解析出的合约不在符号表中，按合约地址查询价格
+    price, err := ec.GetTokenPrice(context.Background(), "Token.Example.xyz")
+    assert.NoError(t, err)
+    assert.Equal(t, token.Hex(), price.TokenAddress)
+    assert.Equal(t, "2.5", price.PriceUSD.String())
+    assert.Equal(t, []string{"/simple/token_price/ethereum"}, paths)
+
+    _, err = ec.GetTokenPrice(context.Background(), "missing.example.xyz")
+    assert.Error(t, err)
+}
+
+func TestENSCache(t *testing.T) {
+    cache := newENSCache(50 * time.Millisecond)
+    addr := common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045")
+
+    cache.setForward("vitalik.eth", addr, true)
+    entry, ok := cache.getForward("vitalik.eth")
+    assert.True(t, ok)
+    assert.True(t, entry.found)
+    assert.Equal(t, addr, entry.address)
+
+    // 没有记录的结果同样缓存
+    cache.setReverse(addr, "", false)
+    entry, ok = cache.getReverse(addr)
+    assert.True(t, ok)
+    assert.False(t, entry.found)
+
+    time.Sleep(60 * time.Millisecond)
+    _, ok = cache.getForward("vitalik.eth")
+    assert.False(t, ok)
+    _, ok = cache.getReverse(addr)
+    assert.False(t, ok)
+}
@@ -0,0 +1,174 @@
+//精确输出 swap
+package ethereum
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+    "time"
+
+    "github.com/ethereum/go-ethereum/accounts/abi"
+    "github.com/ethereum/go-ethereum/accounts/abi/bind"
+    "github.com/ethereum/go-ethereum/common"
+    "go.uber.org/zap"
+
+    "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
+)
+
+const (
+    TradeTypeExactInput  = "exact_input"
+    TradeTypeExactOutput = "exact_output"
+)
+
+// 写入交易的数量限制: 精确输入时限制最少输出，精确输出时限制最多输入
+type swapLimits struct {
+    AmountIn     *big.Int
+    AmountOut    *big.Int
+    AmountInMax  *big.Int
+    AmountOutMin *big.Int
+    Deadline     time.Time
+}
+
+func newSwapLimits(pair *swapPair, amountIn, amountOut *big.Int, slippage decimal.Decimal) *swapLimits {
+    limits := &swapLimits{
+        AmountIn:     amountIn,
+        AmountOut:    amountOut,
+        AmountInMax:  amountIn,
+        AmountOutMin: amountOut,
+        Deadline:     pair.Deadline,
+    }
+    if pair.ExactOutput {
+        limits.AmountInMax = applySlippageMax(amountIn, slippage)
+    } else {
+        limits.AmountOutMin = applySlippage(amountOut, slippage)
+    }
+    // 执行报价时使用报价时算出的限制，不按实时报价重新计算滑点
+    if pair.Pinned != nil {
+        limits.AmountInMax = pair.Pinned.AmountInMax
+        limits.AmountOutMin = pair.Pinned.AmountOutMin
+    }
+    return limits
+}
+
+func setSwapAmounts(result *SwapResponse, pair *swapPair, limits *swapLimits) {
+    deadline := pair.Deadline
+    result.limits = limits
+    result.Recipient = pair.Recipient.Hex()
+    result.Deadline = &deadline
+    result.TradeType = TradeTypeExactInput
+    result.InputAmount = decimal.FormatBalance(limits.AmountIn, pair.FromDecimals)
+    result.EstimatedOutput = decimal.FormatBalance(limits.AmountOut, pair.ToDecimals)
+    result.MinOutput = decimal.FormatBalance(limits.AmountOutMin, pair.ToDecimals)
+    if pair.ExactOutput {
+        maxInput := decimal.FormatBalance(limits.AmountInMax, pair.FromDecimals)
+        result.TradeType = TradeTypeExactOutput
+        result.MaxInput = &maxInput
+    }
+}
+
+// 按滑点计算最多输入，向上取整
+func applySlippageMax(amountIn *big.Int, slippage decimal.Decimal) *big.Int {
+    return decimal.NewFromBigInt(amountIn, 0).Mul(decimal.NewFromInt(1).Add(slippage)).Ceil().BigInt()
+}
+
+// 在所有候选路径中选择 V2 所需输入最少的路径
+func (ec *EthereumClient) findBestV2RouteExactOut(ctx context.Context, fromToken, toToken common.Address, amountOut *big.Int) (*v2Route, error) {
+    var best *v2Route
+    var lastErr error
+    for _, path := range ec.candidatePaths(fromToken, toToken) {
+        route, err := ec.quoteV2PathExactOut(ctx, path, amountOut)
+        if err != nil {
+            lastErr = err
+            continue
+        }
+        if best == nil || route.AmountIn().Cmp(best.AmountIn()) < 0 {
+            best = route
+        }
+    }
+
+    if best == nil {
+        return nil, fmt.Errorf("no Uniswap V2 route for %s/%s: %w", fromToken.Hex(), toToken.Hex(), lastErr)
+    }
+    return best, nil
+}
+
+// 通过 getAmountsIn 反推每一跳需要的数量，Amounts 的最后一项为精确输出
+func (ec *EthereumClient) quoteV2PathExactOut(ctx context.Context, path []common.Address, amountOut *big.Int) (*v2Route, error) {
+    pairs, err := ec.v2Pairs(ctx, path)
+    if err != nil {
+        return nil, err
+    }
+
+    router := bind.NewBoundContract(common.HexToAddress(ec.config.UniswapV2Router), uniswapV2RouterABI, ec.caller, nil, nil)
+    var amountsIn []interface{}
+    if err := router.Call(&bind.CallOpts{Context: ctx}, &amountsIn, "getAmountsIn", amountOut, path); err != nil {
+        return nil, fmt.Errorf("failed to quote Uniswap V2 input: %w", err)
+    }
+    amounts := *abi.ConvertType(amountsIn[0], new([]*big.Int)).(*[]*big.Int)
+    if len(amounts) != len(path) {
+        return nil, fmt.Errorf("unexpected getAmountsIn result length %d", len(amounts))
+    }
+
+    return &v2Route{Path: path, Pairs: pairs, Amounts: amounts}, nil
+}
+
+// 遍历所有手续费档位，返回所需输入最少的报价
+func (ec *EthereumClient) estimateV3Input(ctx context.Context, fromToken, toToken common.Address, amountOut *big.Int) (*v3Quote, error) {
+    var best *v3Quote
+    for _, fee := range v3FeeTiers {
+        quote, err := ec.quoteV3ExactOutputSingle(ctx, fromToken, toToken, amountOut, fee)
+        if err != nil {
+            ec.logger.Debug("V3 exact output quote failed",
+                zap.Uint32("fee", fee),
+                zap.Error(err),
+            )
+            continue
+        }
+        if best == nil || quote.AmountIn.Cmp(best.AmountIn) < 0 {
+            best = quote
+        }
+    }
+
+    if best == nil {
+        return nil, fmt.Errorf("no Uniswap V3 pool with liquidity for %s/%s", fromToken.Hex(), toToken.Hex())
+    }
+    return best, nil
+}
+
+func (ec *EthereumClient) quoteV3ExactOutputSingle(ctx context.Context, fromToken, toToken common.Address, amountOut *big.Int, fee uint32) (*v3Quote, error) {
+    quoter := bind.NewBoundContract(common.HexToAddress(ec.config.UniswapV3Quoter), uniswapV3QuoterABI, ec.caller, nil, nil)
+
+    var out []interface{}
+    err := quoter.Call(&bind.CallOpts{Context: ctx}, &out, "quoteExactOutputSingle", v3QuoteExactOutputSingleParams{
+        TokenIn:           fromToken,
+        TokenOut:          toToken,
+        Amount:            amountOut,
+        Fee:               big.NewInt(int64(fee)),
+        SqrtPriceLimitX96: big.NewInt(0),
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return &v3Quote{
+        Fee:               fee,
+        AmountIn:          abi.ConvertType(out[0], new(big.Int)).(*big.Int),
+        AmountOut:         amountOut,
+        SqrtPriceX96After: abi.ConvertType(out[1], new(big.Int)).(*big.Int),
+        TicksCrossed:      *abi.ConvertType(out[2], new(uint32)).(*uint32),
+        GasEstimate:       abi.ConvertType(out[3], new(big.Int)).(*big.Int),
+    }, nil
+}
+
+func (ec *EthereumClient) buildV2SwapETHForExactTokens(pair *swapPair, amountOut *big.Int, path []common.Address) ([]byte, error) {
+    // value 按最多输入发送，router 会把多余的 ETH 退回给发送方
+    return uniswapV2RouterABI.Pack("swapETHForExactTokens", amountOut, path, pair.Recipient, pair.deadlineUnix())
+}
+
+func (ec *EthereumClient) buildV2SwapTokensForExactETH(pair *swapPair, amountOut, amountInMax *big.Int, path []common.Address) ([]byte, error) {
+    return uniswapV2RouterABI.Pack("swapTokensForExactETH", amountOut, amountInMax, path, pair.Recipient, pair.deadlineUnix())
+}
+
+func (ec *EthereumClient) buildV2SwapTokensForExactTokens(pair *swapPair, amountOut, amountInMax *big.Int, path []common.Address) ([]byte, error) {
+    return uniswapV2RouterABI.Pack("swapTokensForExactTokens", amountOut, amountInMax, path, pair.Recipient, pair.deadlineUnix())
+}
@@ -0,0 +1,43 @@
+package ethereum
+
+import (
+    "math/big"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+
+    "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
+)
+
+func TestApplySlippage(t *testing.T) {
+    slippage := decimal.NewFromFloat(0.005)
+
+    // 最少输出向下取整: 999 * 0.995 = 994.005
+    assert.Equal(t, big.NewInt(994), applySlippage(big.NewInt(999), slippage))
+    assert.Equal(t, big.NewInt(995), applySlippage(big.NewInt(1000), slippage))
+    assert.Equal(t, big.NewInt(1000), applySlippage(big.NewInt(1000), decimal.Zero))
+}
+
+func TestApplySlippageMax(t *testing.T) {
+    slippage := decimal.NewFromFloat(0.005)
+
+    // 最多输入向上取整: 999 * 1.005 = 1003.995
+    assert.Equal(t, big.NewInt(1004), applySlippageMax(big.NewInt(999), slippage))
+    // 正好整除时不多加
+    assert.Equal(t, big.NewInt(1005), applySlippageMax(big.NewInt(1000), slippage))
+    assert.Equal(t, big.NewInt(1000), applySlippageMax(big.NewInt(1000), decimal.Zero))
+    // 1 wei 的输入也要留出滑点空间
+    assert.Equal(t, big.NewInt(2), applySlippageMax(big.NewInt(1), slippage))
+}
+
+func TestNewSwapLimits(t *testing.T) {
+    slippage := decimal.NewFromFloat(0.01)
+
+    exactIn := newSwapLimits(&swapPair{}, big.NewInt(1000), big.NewInt(5000), slippage)
+    assert.Equal(t, big.NewInt(4950), exactIn.AmountOutMin)
+    assert.Equal(t, big.NewInt(1000), exactIn.AmountInMax)
+
+    exactOut := newSwapLimits(&swapPair{ExactOutput: true}, big.NewInt(1000), big.NewInt(5000), slippage)
+    assert.Equal(t, big.NewInt(1010), exactOut.AmountInMax)
+    assert.Equal(t, big.NewInt(5000), exactOut.AmountOutMin)
+}
@@ -0,0 +1,130 @@
+//swap 执行
+package ethereum
+
+import (
+    "context"
+    "fmt"
+    "time"
+)
+
+type ExecuteSwapRequest struct {
+    // swap_tokens 模拟返回的 quote_id
+    QuoteID string `json:"quote_id"`
+}
+
+type ExecuteSwapResponse struct {
+    QuoteID    string        `json:"quote_id"`
+    TxHash     string        `json:"tx_hash,omitempty"`
+    Nonce      uint64        `json:"nonce"`
+    From       string        `json:"from,omitempty"`
+    To         string        `json:"to,omitempty"`
+    Value      string        `json:"value,omitempty"`
+    GasLimit   uint64        `json:"gas_limit,omitempty"`
+    Submission string        `json:"submission,omitempty"`
+    Quote      *SwapResponse `json:"quote,omitempty"`
+    Success    bool          `json:"success"`
+    Error      *string       `json:"error,omitempty"`
+}
+
+// 用保存的报价参数重新报价，确认价格没有超出滑点范围后签名并广播 router 交易
+func (ec *EthereumClient) ExecuteSwap(ctx context.Context, req *ExecuteSwapRequest) (*ExecuteSwapResponse, error) {
+    // 先取走报价，并发的重复执行会因为找不到报价而失败
+    stored, err := ec.quotes.take(req.QuoteID)
+    if err != nil {
+        return &ExecuteSwapResponse{
+            QuoteID: req.QuoteID,
+            Success: false,
+            Error:   stringPtr(err.Error()),
+        }, nil
+    }
+    if deadline := stored.Response.limits.Deadline; !time.Now().Before(deadline) {
+        return &ExecuteSwapResponse{
+            QuoteID: req.QuoteID,
+            Success: false,
+            Error:   stringPtr(fmt.Sprintf("quote deadline passed at %s, please simulate again", deadline.Format(time.RFC3339))),
+        }, nil
+    }
+
+    sent := false
+    defer func() {
+        if !sent {
+            ec.quotes.restore(stored)
+        }
+    }()
+
+    quote, err := ec.simulateSwap(ctx, &stored.Request, stored.Response.limits)
+    if err != nil {
+        return nil, err
+    }
+    if !quote.Success {
+        return &ExecuteSwapResponse{
+            QuoteID: req.QuoteID,
+            Quote:   quote,
+            Success: false,
+            Error:   quote.Error,
+        }, nil
+    }
+
+    if quote.ApprovalNeeded {
+        return &ExecuteSwapResponse{
+            QuoteID: req.QuoteID,
+            Quote:   quote,
+            Success: false,
+            Error: stringPtr(fmt.Sprintf("%s allowance for %s is %s, need %s; call approve_token first",
+                quote.Approval.Token, quote.Approval.Spender, quote.Approval.Allowance.String(), quote.Approval.Required.String())),
+        }, nil
+    }
+
+    // 交易使用报价时的最少输出或最多输入，实时报价满足不了时拒绝执行
+    if drift := quoteDrift(stored, quote); drift != "" {
+        return &ExecuteSwapResponse{
+            QuoteID: req.QuoteID,
+            Quote:   quote,
+            Success: false,
+            Error:   stringPtr(drift),
+        }, nil
+    }
+
+    tx, err := ec.sendTransaction(ctx, req.QuoteID, quote.tx.To, quote.tx.Data, quote.tx.Value, quote.GasEstimate)
+    if err != nil {
+        return &ExecuteSwapResponse{
+            QuoteID: req.QuoteID,
+            Quote:   quote,
+            Success: false,
+            Error:   stringPtr(err.Error()),
+        }, nil
+    }
+    sent = true
+
+    return &ExecuteSwapResponse{
+        QuoteID:    req.QuoteID,
+        TxHash:     tx.Hash().Hex(),
+        Nonce:      tx.Nonce(),
+        From:       ec.walletMgr.GetAddress().Hex(),
+        To:         tx.To().Hex(),
+        Value:      tx.Value().String(),
+        GasLimit:   tx.Gas(),
+        Submission: ec.txs.submission(tx.Hash()),
+        Quote:      quote,
+        Success:    true,
+    }, nil
+}
+
+// 精确输入时实时输出不能低于报价的最少输出，精确输出时实时输入不能高于报价的最多输入
+func quoteDrift(stored *StoredQuote, live *SwapResponse) string {
+    pinned := stored.Response.limits
+    slippage := stored.Request.SlippageTolerance
+    if stored.Request.TradeType == TradeTypeExactOutput {
+        if live.limits.AmountIn.Cmp(pinned.AmountInMax) > 0 {
+            return fmt.Sprintf("live quote requires %s input, above the quoted maximum %s (quoted %s at block %d with %s slippage), refusing to execute",
+                live.InputAmount.String(), stored.Response.MaxInput.String(), stored.Response.InputAmount.String(), stored.BlockNumber, slippage.String())
+        }
+        return ""
+    }
+
+    if live.limits.AmountOut.Cmp(pinned.AmountOutMin) < 0 {
+        return fmt.Sprintf("live quote %s is below the quoted minimum %s (quoted %s at block %d with %s slippage), refusing to execute",
+            live.EstimatedOutput.String(), stored.Response.MinOutput.String(), stored.Response.EstimatedOutput.String(), stored.BlockNumber, slippage.String())
+    }
+    return ""
+}
@@ -0,0 +1,68 @@
+package ethereum
+
+import (
+    "context"
+    "math/big"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+
+    "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
+)
+
+func TestNewSwapLimits_Pinned(t *testing.T) {
+    slippage := decimal.NewFromFloat(0.05)
+    quoted := newSwapLimits(&swapPair{}, big.NewInt(1000), big.NewInt(2000), slippage)
+    assert.Equal(t, big.NewInt(1900), quoted.AmountOutMin)
+
+    // 执行时实时输出更低，最少输出仍然是报价时的 1900 而不是 1800 * 0.95
+    live := newSwapLimits(&swapPair{Pinned: quoted}, big.NewInt(1000), big.NewInt(1800), slippage)
+    assert.Equal(t, big.NewInt(1900), live.AmountOutMin)
+}
+
+func TestQuoteDrift(t *testing.T) {
+    slippage := decimal.NewFromFloat(0.05)
+    quoted := newSwapLimits(&swapPair{}, big.NewInt(1000), big.NewInt(2000), slippage)
+    stored := &StoredQuote{
+        Request:  SwapRequest{SlippageTolerance: slippage},
+        Response: &SwapResponse{limits: quoted},
+    }
+
+    ok := newSwapLimits(&swapPair{Pinned: quoted}, big.NewInt(1000), big.NewInt(1950), slippage)
+    assert.Empty(t, quoteDrift(stored, &SwapResponse{limits: ok}))
+
+    below := newSwapLimits(&swapPair{Pinned: quoted}, big.NewInt(1000), big.NewInt(1899), slippage)
+    assert.NotEmpty(t, quoteDrift(stored, &SwapResponse{limits: below}))
+
+    // 精确输出: 实时输入不能超过报价的最多输入
+    exactOut := &swapPair{ExactOutput: true}
+    quotedOut := newSwapLimits(exactOut, big.NewInt(1000), big.NewInt(2000), slippage)
+    assert.Equal(t, big.NewInt(1050), quotedOut.AmountInMax)
+    maxInput := decimal.NewFromInt(1050)
+    stored = &StoredQuote{
+        Request:  SwapRequest{SlippageTolerance: slippage, TradeType: TradeTypeExactOutput},
+        Response: &SwapResponse{limits: quotedOut, MaxInput: &maxInput},
+    }
+
+    within := newSwapLimits(&swapPair{ExactOutput: true, Pinned: quotedOut}, big.NewInt(1050), big.NewInt(2000), slippage)
+    assert.Empty(t, quoteDrift(stored, &SwapResponse{limits: within}))
+
+    above := newSwapLimits(&swapPair{ExactOutput: true, Pinned: quotedOut}, big.NewInt(1051), big.NewInt(2000), slippage)
+    assert.NotEmpty(t, quoteDrift(stored, &SwapResponse{limits: above}))
+}
+
+func TestExecuteSwap_DeadlinePassed(t *testing.T) {
+    ec := &EthereumClient{quotes: newQuoteStore(time.Minute)}
+    deadline := time.Now().Add(-time.Second)
+    pair := &swapPair{Deadline: deadline}
+    limits := newSwapLimits(pair, big.NewInt(1000), big.NewInt(2000), decimal.NewFromFloat(0.01))
+    assert.Equal(t, deadline, limits.Deadline)
+
+    // 报价还没过期，但写入交易的截止时间已经过了
+    quote := ec.quotes.save(&SwapRequest{FromToken: "ETH", ToToken: "USDC"}, &SwapResponse{Success: true, limits: limits}, 100)
+    resp, err := ec.ExecuteSwap(context.Background(), &ExecuteSwapRequest{QuoteID: quote.ID})
+    assert.NoError(t, err)
+    assert.False(t, resp.Success)
+    assert.Contains(t, *resp.Error, "deadline passed")
+}
@@ -0,0 +1,124 @@
+//gas 代币价格
+package ethereum
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+    "sync"
+    "time"
+
+    "go.uber.org/zap"
+
+    "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
+)
+
+// 原生代币价格的缓存时间，避免每次模拟都请求 CoinGecko
+const nativePriceCacheTTL = 60 * time.Second
+
+// gas_cost_usd 的价格来源
+const (
+    GasPriceSourceLive        = "live"
+    GasPriceSourceCached      = "cached"
+    GasPriceSourceStale       = "stale"
+    GasPriceSourceUnavailable = "unavailable"
+)
+
+// 各链支付 gas 的原生代币，未列出的链按 ETH 计算
+var nativeTokenSymbols = map[int64]string{
+    1:     "ETH",
+    10:    "ETH",
+    8453:  "ETH",
+    42161: "ETH",
+    56:    "BNB",
+    100:   "XDAI",
+    137:   "POL",
+    43114: "AVAX",
+}
+
+type nativePrice struct {
+    Symbol string
+    USD    decimal.Decimal
+    Source string
+}
+
+// 查询原生代币的美元价格
+type nativePriceFetcher func(ctx context.Context, symbol string) (decimal.Decimal, error)
+
+type nativePriceCache struct {
+    now func() time.Time
+
+    mu        sync.Mutex
+    price     decimal.Decimal
+    fetchedAt time.Time
+}
+
+func newNativePriceCache() *nativePriceCache {
+    return &nativePriceCache{now: time.Now}
+}
+
+func nativeTokenSymbol(chainID *big.Int) string {
+    if chainID != nil {
+        if symbol, ok := nativeTokenSymbols[chainID.Int64()]; ok {
+            return symbol
+        }
+    }
+    return "ETH"
+}
+
+// 使用和 GetTokenPrice 相同的价格源，请求失败时退回到过期的缓存值
+func (ec *EthereumClient) nativeTokenPrice(ctx context.Context) *nativePrice {
+    symbol := nativeTokenSymbol(ec.walletMgr.GetChainID())
+    price, err := ec.nativePrice.get(ctx, symbol, func(ctx context.Context, symbol string) (decimal.Decimal, error) {
+        priceUSD, _, err := ec.fetchPriceFromCoinGecko(ctx, symbol)
+        return priceUSD, err
+    })
+    if err != nil {
+        ec.logger.Warn("Failed to fetch native token price for gas cost",
+            zap.String("symbol", symbol),
+            zap.Error(err),
+        )
+    }
+    return price
+}
+
+// 缓存未过期时直接返回，请求失败时返回过期的缓存值 (stale) 或 unavailable，同时返回请求的错误
+func (c *nativePriceCache) get(ctx context.Context, symbol string, fetch nativePriceFetcher) (*nativePrice, error) {
+    // 持锁请求，自动路由并行模拟时只请求一次
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if !c.fetchedAt.IsZero() && c.now().Sub(c.fetchedAt) < nativePriceCacheTTL {
+        return &nativePrice{Symbol: symbol, USD: c.price, Source: GasPriceSourceCached}, nil
+    }
+
+    priceUSD, err := fetch(ctx, symbol)
+    if err == nil && !priceUSD.IsPositive() {
+        err = fmt.Errorf("invalid %s price %s", symbol, priceUSD.String())
+    }
+    if err == nil {
+        c.price = priceUSD
+        c.fetchedAt = c.now()
+        return &nativePrice{Symbol: symbol, USD: priceUSD, Source: GasPriceSourceLive}, nil
+    }
+
+    if !c.fetchedAt.IsZero() {
+        return &nativePrice{Symbol: symbol, USD: c.price, Source: GasPriceSourceStale}, err
+    }
+    return &nativePrice{Symbol: symbol, USD: decimal.Zero, Source: GasPriceSourceUnavailable}, err
+}
+
+// 价格不可用时返回 0，调用方通过 GasCostSource 判断
+func (ec *EthereumClient) calculateGasCostUSD(gasEstimate uint64, gasPrice *big.Int, price *nativePrice) decimal.Decimal {
+    gasCost := decimal.FromWei(big.NewInt(0).Mul(gasPrice, big.NewInt(int64(gasEstimate))))
+    return gasCost.Mul(price.USD)
+}
+
+func setGasCostFields(resp *SwapResponse, price *nativePrice) {
+    resp.GasToken = price.Symbol
+    resp.GasCostSource = price.Source
+    if price.Source != GasPriceSourceUnavailable {
+        usd := price.USD
+        resp.GasTokenPrice = &usd
+    }
+}
@@ -0,0 +1,93 @@
+package ethereum
+
+import (
+    "context"
+    "errors"
+    "math/big"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+
+    "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
+)
+
+func TestNativeTokenSymbol(t *testing.T) {
+    tests := []struct {
+        chainID *big.Int
+        want    string
+    }{
+        {big.NewInt(1), "ETH"},
+        {big.NewInt(8453), "ETH"},
+        {big.NewInt(56), "BNB"},
+        {big.NewInt(137), "POL"},
+        {big.NewInt(43114), "AVAX"},
+        {big.NewInt(999999), "ETH"},
+        {nil, "ETH"},
+    }
+    for _, tt := range tests {
+        assert.Equal(t, tt.want, nativeTokenSymbol(tt.chainID))
+    }
+}
+
+func TestNativePriceCache(t *testing.T) {
+    now := time.Unix(1700000000, 0)
+    cache := newNativePriceCache()
+    cache.now = func() time.Time { return now }
+
+    calls := 0
+    var fetchErr error
+    price := decimal.NewFromInt(3000)
+    fetch := func(ctx context.Context, symbol string) (decimal.Decimal, error) {
+        calls++
+        return price, fetchErr
+    }
+    ctx := context.Background()
+
+    tests := []struct {
+        name      string
+        advance   time.Duration
+        price     decimal.Decimal
+        err       error
+        wantUSD   decimal.Decimal
+        wantFrom  string
+        wantCalls int
+    }{
+        // 第一次请求失败且没有缓存
+        {"unavailable", 0, decimal.NewFromInt(3000), errors.New("rate limited"), decimal.Zero, GasPriceSourceUnavailable, 1},
+        {"zero price", 0, decimal.Zero, nil, decimal.Zero, GasPriceSourceUnavailable, 2},
+        {"live", 0, decimal.NewFromInt(3000), nil, decimal.NewFromInt(3000), GasPriceSourceLive, 3},
+        // 60 秒内不再请求
+        {"cached", 59 * time.Second, decimal.NewFromInt(3100), nil, decimal.NewFromInt(3000), GasPriceSourceCached, 3},
+        {"refreshed", 2 * time.Second, decimal.NewFromInt(3100), nil, decimal.NewFromInt(3100), GasPriceSourceLive, 4},
+        // 缓存过期后请求失败，返回过期的值
+        {"stale", 61 * time.Second, decimal.NewFromInt(3200), errors.New("timeout"), decimal.NewFromInt(3100), GasPriceSourceStale, 5},
+    }
+    for _, tt := range tests {
+        now = now.Add(tt.advance)
+        price, fetchErr = tt.price, tt.err
+
+        got, err := cache.get(ctx, "ETH", fetch)
+        assert.Equal(t, tt.wantFrom, got.Source, tt.name)
+        assert.True(t, tt.wantUSD.Equal(got.USD), tt.name)
+        assert.Equal(t, "ETH", got.Symbol, tt.name)
+        assert.Equal(t, tt.wantCalls, calls, tt.name)
+        if tt.wantFrom == GasPriceSourceUnavailable || tt.wantFrom == GasPriceSourceStale {
+            assert.Error(t, err, tt.name)
+        } else {
+            assert.NoError(t, err, tt.name)
+        }
+    }
+}
+
+func TestSetGasCostFields(t *testing.T) {
+    resp := &SwapResponse{}
+    setGasCostFields(resp, &nativePrice{Symbol: "BNB", USD: decimal.NewFromInt(600), Source: GasPriceSourceStale})
+    assert.Equal(t, "BNB", resp.GasToken)
+    assert.Equal(t, GasPriceSourceStale, resp.GasCostSource)
+    assert.True(t, decimal.NewFromInt(600).Equal(*resp.GasTokenPrice))
+
+    resp = &SwapResponse{}
+    setGasCostFields(resp, &nativePrice{Symbol: "ETH", USD: decimal.Zero, Source: GasPriceSourceUnavailable})
+    assert.Nil(t, resp.GasTokenPrice)
+}
@@ -0,0 +1,141 @@
+//价格影响计算
+package ethereum
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "math/big"
+
+    "github.com/ethereum/go-ethereum/accounts/abi"
+    "github.com/ethereum/go-ethereum/accounts/abi/bind"
+    "github.com/ethereum/go-ethereum/common"
+
+    "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
+)
+
+// 价格比值保留的小数位，避免低价代币被截断为 0
+const priceRatioPrecision = 36
+
+// 2^192，V3 中 price = sqrtPriceX96^2 / 2^192
+var q192 = new(big.Int).Lsh(big.NewInt(1), 192)
+
+// 中间价: 每单位输入代币可换得的输出代币数量(按精度换算)，不含手续费
+func (ec *EthereumClient) v2MidPrice(ctx context.Context, route *v2Route) (*decimal.Decimal, error) {
+    midPrice := decimal.NewFromInt(1)
+    for i, pairAddr := range route.Pairs {
+        tokenIn, tokenOut := route.Path[i], route.Path[i+1]
+
+        pair := bind.NewBoundContract(pairAddr, uniswapV2PairABI, ec.caller, nil, nil)
+        var out []interface{}
+        if err := pair.Call(&bind.CallOpts{Context: ctx}, &out, "getReserves"); err != nil {
+            return nil, fmt.Errorf("failed to get reserves of %s: %w", pairAddr.Hex(), err)
+        }
+        reserveIn := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+        reserveOut := abi.ConvertType(out[1], new(big.Int)).(*big.Int)
+        if isToken1(tokenIn, tokenOut) {
+            reserveIn, reserveOut = reserveOut, reserveIn
+        }
+        if reserveIn.Sign() == 0 {
+            return nil, fmt.Errorf("pair %s has no liquidity", pairAddr.Hex())
+        }
+
+        hopPrice, err := ec.adjustedPrice(ctx, reserveOut, reserveIn, tokenIn, tokenOut)
+        if err != nil {
+            return nil, err
+        }
+        midPrice = midPrice.Mul(hopPrice)
+    }
+    return &midPrice, nil
+}
+
+// V3 用交易前池子的 slot0 价格作为中间价
+func (ec *EthereumClient) v3MidPrice(ctx context.Context, route *v3Route) (*decimal.Decimal, error) {
+    factory := bind.NewBoundContract(common.HexToAddress(ec.config.UniswapV3Factory), uniswapV3FactoryABI, ec.caller, nil, nil)
+
+    midPrice := decimal.NewFromInt(1)
+    for i, quote := range route.Quotes {
+        tokenIn, tokenOut := route.Path[i], route.Path[i+1]
+
+        var poolOut []interface{}
+        if err := factory.Call(&bind.CallOpts{Context: ctx}, &poolOut, "getPool", tokenIn, tokenOut, big.NewInt(int64(quote.Fee))); err != nil {
+            return nil, fmt.Errorf("failed to query Uniswap V3 pool: %w", err)
+        }
+        poolAddr := *abi.ConvertType(poolOut[0], new(common.Address)).(*common.Address)
+        if poolAddr == (common.Address{}) {
+            return nil, fmt.Errorf("no Uniswap V3 pool for %s/%s fee %d", tokenIn.Hex(), tokenOut.Hex(), quote.Fee)
+        }
+
+        pool := bind.NewBoundContract(poolAddr, uniswapV3PoolABI, ec.caller, nil, nil)
+        var slot0 []interface{}
+        if err := pool.Call(&bind.CallOpts{Context: ctx}, &slot0, "slot0"); err != nil {
+            return nil, fmt.Errorf("failed to get slot0 of %s: %w", poolAddr.Hex(), err)
+        }
+        sqrtPriceX96 := abi.ConvertType(slot0[0], new(big.Int)).(*big.Int)
+        if sqrtPriceX96.Sign() == 0 {
+            return nil, fmt.Errorf("pool %s is not initialized", poolAddr.Hex())
+        }
+
+        // sqrtPrice^2 / 2^192 是 token1 相对 token0 的价格
+        num := new(big.Int).Mul(sqrtPriceX96, sqrtPriceX96)
+        den := q192
+        if isToken1(tokenIn, tokenOut) {
+            num, den = den, num
+        }
+
+        hopPrice, err := ec.adjustedPrice(ctx, num, den, tokenIn, tokenOut)
+        if err != nil {
+            return nil, err
+        }
+        midPrice = midPrice.Mul(hopPrice)
+    }
+    return &midPrice, nil
+}
+
+// 最小单位下的比值换算成按代币精度表示的价格
+func (ec *EthereumClient) adjustedPrice(ctx context.Context, num, den *big.Int, tokenIn, tokenOut common.Address) (decimal.Decimal, error) {
+    inDecimals, outDecimals, err := ec.hopDecimals(ctx, tokenIn, tokenOut)
+    if err != nil {
+        return decimal.Zero, err
+    }
+    raw := decimal.NewFromBigInt(num, 0).DivRound(decimal.NewFromBigInt(den, 0), priceRatioPrecision)
+    return raw.Shift(int32(inDecimals - outDecimals)), nil
+}
+
+// Uniswap 池子按地址排序，地址较大的是 token1
+func isToken1(token, other common.Address) bool {
+    return bytes.Compare(token.Bytes(), other.Bytes()) > 0
+}
+
+func setPriceImpact(result *SwapResponse, midPrice *decimal.Decimal) {
+    if result.InputAmount.IsZero() {
+        return
+    }
+    result.ExecutionPrice = result.EstimatedOutput.DivRound(result.InputAmount, priceRatioPrecision)
+    if midPrice == nil || midPrice.IsZero() {
+        return
+    }
+
+    // 相对中间价少拿到的比例，包含 LP 手续费
+    impact := decimal.NewFromInt(1).Sub(result.ExecutionPrice.DivRound(*midPrice, priceRatioPrecision)).Mul(decimal.NewFromInt(100)).Round(4)
+    result.MidPrice = midPrice
+    result.PriceImpact = &impact
+}
+
+func (ec *EthereumClient) checkPriceImpact(result *SwapResponse) {
+    if result.PriceImpact == nil {
+        return
+    }
+
+    if limit := ec.config.PriceImpactBlockPercent; limit > 0 && result.PriceImpact.GreaterThan(decimal.NewFromFloat(limit)) {
+        result.Success = false
+        result.Error = stringPtr(fmt.Sprintf("price impact %s%% exceeds the %s%% limit",
+            result.PriceImpact.StringFixed(2), decimal.NewFromFloat(limit).String()))
+        return
+    }
+
+    if limit := ec.config.PriceImpactWarnPercent; limit > 0 && result.PriceImpact.GreaterThan(decimal.NewFromFloat(limit)) {
+        result.ImpactWarning = stringPtr(fmt.Sprintf("price impact %s%% is above %s%%",
+            result.PriceImpact.StringFixed(2), decimal.NewFromFloat(limit).String()))
+    }
+}
@@ -0,0 +1,63 @@
+package ethereum
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+
+    "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
+)
+
+func TestSetPriceImpact(t *testing.T) {
+    result := &SwapResponse{
+        InputAmount:     decimal.NewFromInt(2),
+        EstimatedOutput: decimal.NewFromInt(3960),
+    }
+    midPrice := decimal.NewFromInt(2000)
+    setPriceImpact(result, &midPrice)
+
+    assert.True(t, decimal.NewFromInt(1980).Equal(result.ExecutionPrice))
+    // 1 - 1980 / 2000 = 1%
+    assert.True(t, decimal.NewFromInt(1).Equal(*result.PriceImpact))
+
+    // 没有中间价时只计算成交价
+    result = &SwapResponse{InputAmount: decimal.NewFromInt(2), EstimatedOutput: decimal.NewFromInt(3960)}
+    setPriceImpact(result, nil)
+    assert.Nil(t, result.PriceImpact)
+    assert.True(t, decimal.NewFromInt(1980).Equal(result.ExecutionPrice))
+}
+
+func TestCheckPriceImpact(t *testing.T) {
+    ec := &EthereumClient{config: &EthereumConfig{PriceImpactWarnPercent: 1, PriceImpactBlockPercent: 5}}
+    withImpact := func(percent float64) *SwapResponse {
+        impact := decimal.NewFromFloat(percent)
+        return &SwapResponse{PriceImpact: &impact, Success: true}
+    }
+
+    result := withImpact(0.5)
+    ec.checkPriceImpact(result)
+    assert.True(t, result.Success)
+    assert.Nil(t, result.ImpactWarning)
+
+    // 等于阈值不触发
+    result = withImpact(1)
+    ec.checkPriceImpact(result)
+    assert.Nil(t, result.ImpactWarning)
+
+    result = withImpact(2.5)
+    ec.checkPriceImpact(result)
+    assert.True(t, result.Success)
+    assert.NotNil(t, result.ImpactWarning)
+
+    result = withImpact(5.01)
+    ec.checkPriceImpact(result)
+    assert.False(t, result.Success)
+    assert.NotNil(t, result.Error)
+
+    // 阈值为 0 时不启用
+    ec.config = &EthereumConfig{}
+    result = withImpact(50)
+    ec.checkPriceImpact(result)
+    assert.True(t, result.Success)
+    assert.Nil(t, result.ImpactWarning)
+}
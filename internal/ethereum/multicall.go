@@ -0,0 +1,84 @@
+//Multicall3 批量只读调用
+package ethereum
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "math/big"
+
+    geth "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/accounts/abi"
+    "github.com/ethereum/go-ethereum/accounts/abi/bind"
+    "github.com/ethereum/go-ethereum/common"
+)
+
+// Multicall3 在主网和主要 L2 上部署在同一地址
+const defaultMulticall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+// 单次 eth_call 打包的调用数上限，避免超过节点的 gas 限制
+const multicallBatchSize = 300
+
+// 链上没有 Multicall3 合约时返回，调用方改为逐个调用
+var errMulticallUnavailable = errors.New("multicall3 contract not deployed")
+
+type multicaller struct {
+    caller  bind.ContractCaller
+    address common.Address
+}
+
+func newMulticaller(caller bind.ContractCaller, address string) *multicaller {
+    if address == "" {
+        address = defaultMulticall3Address
+    }
+    return &multicaller{
+        caller:  caller,
+        address: common.HexToAddress(address),
+    }
+}
+
+// 批量执行只读调用，AllowFailure 为 true 的调用失败时对应结果的 Success 为 false，不影响其他调用
+func (m *multicaller) aggregate(ctx context.Context, blockNumber *big.Int, calls []multicall3Call) ([]multicall3Result, error) {
+    results := make([]multicall3Result, 0, len(calls))
+    for start := 0; start < len(calls); start += multicallBatchSize {
+        end := start + multicallBatchSize
+        if end > len(calls) {
+            end = len(calls)
+        }
+        batch, err := m.aggregateBatch(ctx, blockNumber, calls[start:end])
+        if err != nil {
+            return nil, err
+        }
+        results = append(results, batch...)
+    }
+    return results, nil
+}
+
+func (m *multicaller) aggregateBatch(ctx context.Context, blockNumber *big.Int, calls []multicall3Call) ([]multicall3Result, error) {
+    data, err := multicall3ABI.Pack("aggregate3", calls)
+    if err != nil {
+        return nil, fmt.Errorf("failed to build multicall data: %w", err)
+    }
+
+    out, err := m.caller.CallContract(ctx, geth.CallMsg{To: &m.address, Data: data}, blockNumber)
+    if err != nil {
+        return nil, fmt.Errorf("multicall failed: %w", err)
+    }
+    if len(out) == 0 {
+        code, err := m.caller.CodeAt(ctx, m.address, blockNumber)
+        if err == nil && len(code) == 0 {
+            return nil, errMulticallUnavailable
+        }
+        return nil, fmt.Errorf("multicall returned no data")
+    }
+
+    unpacked, err := multicall3ABI.Unpack("aggregate3", out)
+    if err != nil {
+        return nil, fmt.Errorf("failed to decode multicall result: %w", err)
+    }
+    results := *abi.ConvertType(unpacked[0], new([]multicall3Result)).(*[]multicall3Result)
+    if len(results) != len(calls) {
+        return nil, fmt.Errorf("multicall returned %d results for %d calls", len(results), len(calls))
+    }
+    return results, nil
+}
@@ -0,0 +1,138 @@
+package ethereum
+
+import (
+    "context"
+    "errors"
+    "math/big"
+    "testing"
+
+    geth "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/accounts/abi"
+    "github.com/ethereum/go-ethereum/accounts/abi/bind"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/stretchr/testify/assert"
+    "go.uber.org/zap"
+)
+
+// 模拟链上的 Multicall3: 按目标合约和方法选择器返回预设结果，没有预设的合约视为 EOA
+type fakeMulticall struct {
+    deployed  bool
+    contracts map[common.Address]map[string][]byte
+    requests  int
+}
+
+func (f *fakeMulticall) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+    if f.deployed {
+        return []byte{0x60, 0x80}, nil
+    }
+    return nil, nil
+}
+
+func (f *fakeMulticall) CallContract(ctx context.Context, call geth.CallMsg, blockNumber *big.Int) ([]byte, error) {
+    f.requests++
+    if !f.deployed {
+        return nil, nil
+    }
+
+    method := multicall3ABI.Methods["aggregate3"]
+    args, err := method.Inputs.Unpack(call.Data[4:])
+    if err != nil {
+        return nil, err
+    }
+    calls := *abi.ConvertType(args[0], new([]multicall3Call)).(*[]multicall3Call)
+
+    results := make([]multicall3Result, len(calls))
+    for i, c := range calls {
+        methods, ok := f.contracts[c.Target]
+        if !ok {
+            results[i] = multicall3Result{Success: true}
+            continue
+        }
+        out, ok := methods[string(c.CallData[:4])]
+        results[i] = multicall3Result{Success: ok, ReturnData: out}
+    }
+    return method.Outputs.Pack(results)
+}
+
+func packERC20Output(t *testing.T, contractABI abi.ABI, method string, value interface{}) []byte {
+    out, err := contractABI.Methods[method].Outputs.Pack(value)
+    assert.NoError(t, err)
+    return out
+}
+
+func TestERC20Infos_Multicall(t *testing.T) {
+    usdc := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+    mkr := common.HexToAddress("0x9f8F72aA9304c8B593d555F12eF6589cC3A579A2")
+    eoa := common.HexToAddress("0x742d35Cc6634C0532925a3b8D7a2a5c4A7A6A5a5")
+
+    var mkrSymbol [32]byte
+    copy(mkrSymbol[:], "MKR")
+
+    fake := &fakeMulticall{
+        deployed: true,
+        contracts: map[common.Address]map[string][]byte{
+            usdc: {
+                string(erc20ABI.Methods["balanceOf"].ID): packERC20Output(t, erc20ABI, "balanceOf", big.NewInt(2500000)),
+                string(erc20ABI.Methods["decimals"].ID):  packERC20Output(t, erc20ABI, "decimals", uint8(6)),
+                string(erc20ABI.Methods["symbol"].ID):    packERC20Output(t, erc20ABI, "symbol", "USDC"),
+                string(erc20ABI.Methods["name"].ID):      packERC20Output(t, erc20ABI, "name", "USD Coin"),
+            },
+            // bytes32 symbol，没有 name 和 decimals
+            mkr: {
+                string(erc20ABI.Methods["balanceOf"].ID): packERC20Output(t, erc20ABI, "balanceOf", big.NewInt(7)),
+                string(erc20ABI.Methods["symbol"].ID):    packERC20Output(t, erc20Bytes32ABI, "symbol", mkrSymbol),
+            },
+        },
+    }
+    ec := &EthereumClient{
+        logger:        zap.NewNop(),
+        decimalsCache: make(map[common.Address]int),
+        multicall:     newMulticaller(fake, ""),
+    }
+
+    infos, err := ec.erc20Infos(&bind.CallOpts{Context: context.Background()}, eoa, []common.Address{usdc, mkr, eoa})
+    assert.NoError(t, err)
+    assert.Equal(t, 1, fake.requests)
+    assert.Len(t, infos, 3)
+
+    assert.NoError(t, infos[0].Err)
+    assert.Equal(t, big.NewInt(2500000), infos[0].Balance)
+    assert.Equal(t, 6, infos[0].Decimals)
+    assert.Equal(t, "USDC", *infos[0].Symbol)
+    assert.Equal(t, "USD Coin", *infos[0].Name)
+    assert.Equal(t, 6, ec.decimalsCache[usdc])
+
+    assert.NoError(t, infos[1].Err)
+    assert.Equal(t, big.NewInt(7), infos[1].Balance)
+    assert.Equal(t, 18, infos[1].Decimals)
+    assert.Equal(t, "MKR", *infos[1].Symbol)
+    assert.Nil(t, infos[1].Name)
+
+    assert.True(t, errors.Is(infos[2].Err, ErrNotContract))
+}
+
+func TestMulticall_Batches(t *testing.T) {
+    token := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+    fake := &fakeMulticall{
+        deployed: true,
+        contracts: map[common.Address]map[string][]byte{
+            token: {string(erc20ABI.Methods["decimals"].ID): packERC20Output(t, erc20ABI, "decimals", uint8(6))},
+        },
+    }
+    m := newMulticaller(fake, "")
+
+    calls := make([]multicall3Call, multicallBatchSize+1)
+    for i := range calls {
+        calls[i] = multicall3Call{Target: token, AllowFailure: true, CallData: erc20ABI.Methods["decimals"].ID}
+    }
+    results, err := m.aggregate(context.Background(), nil, calls)
+    assert.NoError(t, err)
+    assert.Len(t, results, len(calls))
+    assert.Equal(t, 2, fake.requests)
+}
+
+func TestMulticall_NotDeployed(t *testing.T) {
+    m := newMulticaller(&fakeMulticall{}, "")
+    _, err := m.aggregate(context.Background(), nil, []multicall3Call{{Target: common.HexToAddress("0x01"), AllowFailure: true}})
+    assert.True(t, errors.Is(err, errMulticallUnavailable))
+}
@@ -0,0 +1,218 @@
+//多跳路径查找
+package ethereum
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+
+    "github.com/ethereum/go-ethereum/common"
+    "go.uber.org/zap"
+
+    "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
+)
+
+// swap 路径中的一跳
+type SwapHop struct {
+    TokenIn        string          `json:"token_in"`
+    TokenOut       string          `json:"token_out"`
+    FeeTier        *uint32         `json:"fee_tier,omitempty"`
+    AmountIn       decimal.Decimal `json:"amount_in"`
+    AmountOut      decimal.Decimal `json:"amount_out"`
+    SqrtPriceAfter *string         `json:"sqrt_price_x96_after,omitempty"`
+}
+
+type v2Route struct {
+    Path    []common.Address
+    Pairs   []common.Address
+    Amounts []*big.Int
+}
+
+func (r *v2Route) AmountIn() *big.Int {
+    return r.Amounts[0]
+}
+
+func (r *v2Route) AmountOut() *big.Int {
+    return r.Amounts[len(r.Amounts)-1]
+}
+
+type v3Route struct {
+    Path        []common.Address
+    Quotes      []*v3Quote
+    ExactOutput bool
+}
+
+func (r *v3Route) AmountIn() *big.Int {
+    return r.Quotes[0].AmountIn
+}
+
+func (r *v3Route) AmountOut() *big.Int {
+    return r.Quotes[len(r.Quotes)-1].AmountOut
+}
+
+func (r *v3Route) Fees() []uint32 {
+    fees := make([]uint32, len(r.Quotes))
+    for i, q := range r.Quotes {
+        fees[i] = q.Fee
+    }
+    return fees
+}
+
+func (r *v3Route) TicksCrossed() uint32 {
+    var total uint32
+    for _, q := range r.Quotes {
+        total += q.TicksCrossed
+    }
+    return total
+}
+
+// 直连路径以及经过每个中间代币的两跳路径
+func (ec *EthereumClient) candidatePaths(fromToken, toToken common.Address) [][]common.Address {
+    paths := [][]common.Address{{fromToken, toToken}}
+    for _, base := range ec.baseTokens() {
+        if base == fromToken || base == toToken {
+            continue
+        }
+        paths = append(paths, []common.Address{fromToken, base, toToken})
+    }
+    return paths
+}
+
+func (ec *EthereumClient) baseTokens() []common.Address {
+    var bases []common.Address
+    seen := make(map[common.Address]bool)
+    for _, token := range ec.config.BaseTokens {
+        addr, err := ec.resolveTokenAddress(token)
+        if err != nil {
+            ec.logger.Warn("Ignoring invalid base token", zap.String("token", token), zap.Error(err))
+            continue
+        }
+        if !seen[addr] {
+            seen[addr] = true
+            bases = append(bases, addr)
+        }
+    }
+    return bases
+}
+
+// 在所有候选路径中选择 V2 输出最多的路径
+func (ec *EthereumClient) findBestV2Route(ctx context.Context, fromToken, toToken common.Address, amountIn *big.Int) (*v2Route, error) {
+    var best *v2Route
+    var lastErr error
+    for _, path := range ec.candidatePaths(fromToken, toToken) {
+        route, err := ec.quoteV2Path(ctx, path, amountIn)
+        if err != nil {
+            lastErr = err
+            continue
+        }
+        if best == nil || route.AmountOut().Cmp(best.AmountOut()) > 0 {
+            best = route
+        }
+    }
+
+    if best == nil {
+        return nil, fmt.Errorf("no Uniswap V2 route for %s/%s: %w", fromToken.Hex(), toToken.Hex(), lastErr)
+    }
+    return best, nil
+}
+
+// 在所有候选路径中选择 V3 输出最多的路径，multiHopOnly 时跳过直连路径
+func (ec *EthereumClient) findBestV3Route(ctx context.Context, fromToken, toToken common.Address, amountIn *big.Int, multiHopOnly bool) (*v3Route, error) {
+    var best *v3Route
+    var lastErr error
+    for _, path := range ec.candidatePaths(fromToken, toToken) {
+        if multiHopOnly && len(path) == 2 {
+            continue
+        }
+        route, err := ec.quoteV3Path(ctx, path, amountIn)
+        if err != nil {
+            lastErr = err
+            continue
+        }
+        if best == nil || route.AmountOut().Cmp(best.AmountOut()) > 0 {
+            best = route
+        }
+    }
+
+    if best == nil {
+        if lastErr == nil {
+            lastErr = fmt.Errorf("no candidate paths")
+        }
+        return nil, fmt.Errorf("no Uniswap V3 route for %s/%s: %w", fromToken.Hex(), toToken.Hex(), lastErr)
+    }
+    return best, nil
+}
+
+// 逐跳选择最优手续费档位，上一跳的输出作为下一跳的输入
+func (ec *EthereumClient) quoteV3Path(ctx context.Context, path []common.Address, amountIn *big.Int) (*v3Route, error) {
+    route := &v3Route{Path: path}
+    hopIn := amountIn
+    for i := 0; i < len(path)-1; i++ {
+        quote, err := ec.estimateV3Output(ctx, path[i], path[i+1], hopIn)
+        if err != nil {
+            return nil, err
+        }
+        route.Quotes = append(route.Quotes, quote)
+        hopIn = quote.AmountOut
+    }
+    return route, nil
+}
+
+// V3 exactInput 的路径编码: token(20) | fee(3) | token(20) | ...
+func encodeV3Path(path []common.Address, fees []uint32) []byte {
+    encoded := make([]byte, 0, len(path)*common.AddressLength+len(fees)*3)
+    for i, token := range path {
+        encoded = append(encoded, token.Bytes()...)
+        if i < len(fees) {
+            fee := fees[i]
+            encoded = append(encoded, byte(fee>>16), byte(fee>>8), byte(fee))
+        }
+    }
+    return encoded
+}
+
+func (ec *EthereumClient) v2Hops(ctx context.Context, route *v2Route) ([]SwapHop, error) {
+    // 路径上所有代币的精度一次查询
+    decimals, err := ec.tokensDecimals(ctx, route.Path)
+    if err != nil {
+        return nil, err
+    }
+    hops := make([]SwapHop, 0, len(route.Path)-1)
+    for i := 0; i < len(route.Path)-1; i++ {
+        hops = append(hops, SwapHop{
+            TokenIn:   route.Path[i].Hex(),
+            TokenOut:  route.Path[i+1].Hex(),
+            AmountIn:  decimal.FormatBalance(route.Amounts[i], decimals[i]),
+            AmountOut: decimal.FormatBalance(route.Amounts[i+1], decimals[i+1]),
+        })
+    }
+    return hops, nil
+}
+
+func (ec *EthereumClient) v3Hops(ctx context.Context, route *v3Route) ([]SwapHop, error) {
+    decimals, err := ec.tokensDecimals(ctx, route.Path)
+    if err != nil {
+        return nil, err
+    }
+    hops := make([]SwapHop, 0, len(route.Quotes))
+    for i, quote := range route.Quotes {
+        fee := quote.Fee
+        hops = append(hops, SwapHop{
+            TokenIn:        route.Path[i].Hex(),
+            TokenOut:       route.Path[i+1].Hex(),
+            FeeTier:        &fee,
+            AmountIn:       decimal.FormatBalance(quote.AmountIn, decimals[i]),
+            AmountOut:      decimal.FormatBalance(quote.AmountOut, decimals[i+1]),
+            SqrtPriceAfter: stringPtr(quote.SqrtPriceX96After.String()),
+        })
+    }
+    return hops, nil
+}
+
+func (ec *EthereumClient) hopDecimals(ctx context.Context, tokenIn, tokenOut common.Address) (int, int, error) {
+    decimals, err := ec.tokensDecimals(ctx, []common.Address{tokenIn, tokenOut})
+    if err != nil {
+        return 0, 0, err
+    }
+    return decimals[0], decimals[1], nil
+}
@@ -0,0 +1,178 @@
+package ethereum
+
+import (
+    "context"
+    "errors"
+    "math/big"
+    "testing"
+
+    geth "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/accounts/abi"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/common/hexutil"
+    "github.com/stretchr/testify/assert"
+    "go.uber.org/zap"
+)
+
+var (
+    testWETH = common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+    testUSDC = common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+    testDAI  = common.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F")
+    testWBTC = common.HexToAddress("0x2260FAC5E5542a773Aa44fBCfeDf7C193bc2C599")
+
+    testV2Router  = common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+    testV2Factory = common.HexToAddress("0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f")
+    testV3Quoter  = common.HexToAddress("0x61fFE014bA17989E743c5F6cB21bF9697530B21e")
+    testV3Router  = common.HexToAddress("0x68b3465833fb72A70ecDF485E0e4C7bD8665Fc45")
+)
+
+// 模拟合约的一个方法，返回错误时视为 revert
+type fakeMethod func(args []interface{}) ([]interface{}, error)
+
+type fakeContract struct {
+    abi     abi.ABI
+    methods map[string]fakeMethod
+}
+
+// 按地址分派的只读合约调用，没有注册的地址视为 EOA
+type fakeContracts map[common.Address]*fakeContract
+
+func (f fakeContracts) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+    if _, ok := f[contract]; ok {
+        return []byte{0x60, 0x80}, nil
+    }
+    return nil, nil
+}
+
+func (f fakeContracts) CallContract(ctx context.Context, call geth.CallMsg, blockNumber *big.Int) ([]byte, error) {
+    contract, ok := f[*call.To]
+    if !ok {
+        return nil, nil
+    }
+    method, err := contract.abi.MethodById(call.Data[:4])
+    if err != nil {
+        return nil, err
+    }
+    handle, ok := contract.methods[method.Name]
+    if !ok {
+        return nil, errors.New("execution reverted")
+    }
+    args, err := method.Inputs.Unpack(call.Data[4:])
+    if err != nil {
+        return nil, err
+    }
+    out, err := handle(args)
+    if err != nil {
+        return nil, err
+    }
+    return method.Outputs.Pack(out...)
+}
+
+// 模拟 V2 factory 和 router: pairs 为已存在的交易对，rates 为每一跳每 1000 单位输入的输出
+func fakeV2(pairs map[[2]common.Address]common.Address, rates map[[2]common.Address]int64) fakeContracts {
+    pairFor := func(a, b common.Address) common.Address {
+        if pair, ok := pairs[[2]common.Address{a, b}]; ok {
+            return pair
+        }
+        return pairs[[2]common.Address{b, a}]
+    }
+    return fakeContracts{
+        testV2Factory: {abi: uniswapV2FactoryABI, methods: map[string]fakeMethod{
+            "getPair": func(args []interface{}) ([]interface{}, error) {
+                return []interface{}{pairFor(args[0].(common.Address), args[1].(common.Address))}, nil
+            },
+        }},
+        testV2Router: {abi: uniswapV2RouterABI, methods: map[string]fakeMethod{
+            "getAmountsOut": func(args []interface{}) ([]interface{}, error) {
+                path := args[1].([]common.Address)
+                amounts := []*big.Int{args[0].(*big.Int)}
+                for i := 0; i < len(path)-1; i++ {
+                    rate, ok := rates[[2]common.Address{path[i], path[i+1]}]
+                    if !ok {
+                        return nil, errors.New("execution reverted: UniswapV2Library: INSUFFICIENT_LIQUIDITY")
+                    }
+                    out := new(big.Int).Mul(amounts[i], big.NewInt(rate))
+                    amounts = append(amounts, out.Div(out, big.NewInt(1000)))
+                }
+                return []interface{}{amounts}, nil
+            },
+        }},
+    }
+}
+
+func newRouteTestClient(caller fakeContracts) *EthereumClient {
+    return &EthereumClient{
+        caller: caller,
+        logger: zap.NewNop(),
+        config: &EthereumConfig{
+            UniswapV2Router:  testV2Router.Hex(),
+            UniswapV2Factory: testV2Factory.Hex(),
+            UniswapV3Router:  testV3Router.Hex(),
+            UniswapV3Quoter:  testV3Quoter.Hex(),
+            WETHAddress:      testWETH.Hex(),
+            BaseTokens:       []string{testWETH.Hex(), testUSDC.Hex()},
+        },
+    }
+}
+
+func TestEncodeV3Path(t *testing.T) {
+    weth := common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+    usdc := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+    dai := common.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F")
+
+    // token(20) | fee(3) | token(20) | fee(3) | token(20)
+    encoded := encodeV3Path([]common.Address{weth, usdc, dai}, []uint32{500, 100})
+    assert.Len(t, encoded, 66)
+    assert.Equal(t,
+        "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"+"0001f4"+
+            "a0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"+"000064"+
+            "6b175474e89094c44da98b954eedeac495271d0f",
+        hexutil.Encode(encoded))
+
+    // 最大的手续费档位 1% 也能用 3 字节表示
+    single := encodeV3Path([]common.Address{weth, usdc}, []uint32{10000})
+    assert.Equal(t, []byte{0x00, 0x27, 0x10}, single[20:23])
+}
+
+func TestFindBestV2Route(t *testing.T) {
+    daiUSDC := common.HexToAddress("0xAE461cA67B15dc8dc81CE7615e0320dA1A9aB8D5")
+    daiWETH := common.HexToAddress("0xA478c2975Ab1Ea89e8196811F51A7B7Ade33eB11")
+    wethUSDC := common.HexToAddress("0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc")
+    wethWBTC := common.HexToAddress("0xBb2b8038a1640196FbE3e38816F3e67Cba72D940")
+    ec := newRouteTestClient(fakeV2(
+        map[[2]common.Address]common.Address{
+            {testDAI, testUSDC}:  daiUSDC,
+            {testDAI, testWETH}:  daiWETH,
+            {testWETH, testUSDC}: wethUSDC,
+            {testWETH, testWBTC}: wethWBTC,
+        },
+        map[[2]common.Address]int64{
+            {testDAI, testUSDC}:  990,
+            {testDAI, testWETH}:  500,
+            {testWETH, testUSDC}: 2020,
+            {testWETH, testWBTC}: 40,
+        },
+    ))
+    ctx := context.Background()
+
+    // 直连输出 990000，经过 WETH 输出 1000000 * 0.5 * 2.02 = 1010000
+    route, err := ec.findBestV2Route(ctx, testDAI, testUSDC, big.NewInt(1000000))
+    assert.NoError(t, err)
+    assert.Equal(t, []common.Address{testDAI, testWETH, testUSDC}, route.Path)
+    assert.Equal(t, []common.Address{daiWETH, wethUSDC}, route.Pairs)
+    assert.Equal(t, []*big.Int{big.NewInt(1000000), big.NewInt(500000), big.NewInt(1010000)}, route.Amounts)
+    assert.Equal(t, big.NewInt(1010000), route.AmountOut())
+
+    // 没有直连交易对时只剩经过 WETH 的路径
+    route, err = ec.findBestV2Route(ctx, testDAI, testWBTC, big.NewInt(1000000))
+    assert.NoError(t, err)
+    assert.Equal(t, []common.Address{testDAI, testWETH, testWBTC}, route.Path)
+    assert.Equal(t, big.NewInt(20000), route.AmountOut())
+
+    // 所有路径都缺少交易对
+    unknown := common.HexToAddress("0x1111111111111111111111111111111111111111")
+    _, err = ec.findBestV2Route(ctx, unknown, testUSDC, big.NewInt(1000000))
+    assert.Error(t, err)
+    assert.Contains(t, err.Error(), "no Uniswap V2 route")
+    assert.Contains(t, err.Error(), "no Uniswap V2 pair for")
+}
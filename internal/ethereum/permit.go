@@ -0,0 +1,102 @@
+//EIP-2612 permit 授权
+package ethereum
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+
+    "github.com/ethereum/go-ethereum/accounts/abi"
+    "github.com/ethereum/go-ethereum/accounts/abi/bind"
+    "github.com/ethereum/go-ethereum/common"
+
+    "github.com/your-username/ethereum-trading-mcp/internal/wallet"
+)
+
+const (
+    ApprovalMethodNone    = "none"
+    ApprovalMethodPermit  = "permit"
+    ApprovalMethodPermit2 = "permit2"
+    ApprovalMethodApprove = "approve"
+)
+
+// 大部分 EIP-2612 代币没有 version() 方法，依次尝试常见的版本号
+var permitVersions = []string{"1", "2"}
+
+type permitDomain struct {
+    Name    string
+    Version string
+}
+
+// 通过比对链上 DOMAIN_SEPARATOR 判断代币是否支持 EIP-2612，并找出签名用的 name/version
+func (ec *EthereumClient) tokenPermitDomain(ctx context.Context, token common.Address) (*permitDomain, error) {
+    contract := bind.NewBoundContract(token, erc20ABI, ec.caller, nil, nil)
+    opts := &bind.CallOpts{Context: ctx}
+
+    var out []interface{}
+    if err := contract.Call(opts, &out, "DOMAIN_SEPARATOR"); err != nil {
+        return nil, fmt.Errorf("%s does not support permit: %w", token.Hex(), err)
+    }
+    separator := common.Hash(*abi.ConvertType(out[0], new([32]byte)).(*[32]byte))
+
+    out = nil
+    if err := contract.Call(opts, &out, "name"); err != nil {
+        return nil, fmt.Errorf("failed to get name of %s: %w", token.Hex(), err)
+    }
+    name := *abi.ConvertType(out[0], new(string)).(*string)
+
+    versions := permitVersions
+    out = nil
+    if err := contract.Call(opts, &out, "version"); err == nil {
+        versions = append([]string{*abi.ConvertType(out[0], new(string)).(*string)}, versions...)
+    }
+
+    for _, version := range versions {
+        expected, err := ec.walletMgr.PermitDomainSeparator(name, version, token)
+        if err != nil {
+            return nil, err
+        }
+        if expected == separator {
+            return &permitDomain{Name: name, Version: version}, nil
+        }
+    }
+    return nil, fmt.Errorf("%s uses a non-standard permit domain", token.Hex())
+}
+
+// 签名 EIP-2612 permit 并编码为 V3 router 的 selfPermit 调用，放在 multicall 中的 swap 之前。
+// V2 router 没有 selfPermit，V2 swap 不使用 EIP-2612，只能通过 Permit2 或 approve 授权
+func (ec *EthereumClient) buildSelfPermit(ctx context.Context, approval *TokenApproval, deadline *big.Int) ([]byte, error) {
+    domain, err := ec.tokenPermitDomain(ctx, approval.token)
+    if err != nil {
+        return nil, err
+    }
+
+    contract := bind.NewBoundContract(approval.token, erc20ABI, ec.caller, nil, nil)
+    var out []interface{}
+    if err := contract.Call(&bind.CallOpts{Context: ctx}, &out, "nonces", ec.walletMgr.GetAddress()); err != nil {
+        return nil, fmt.Errorf("failed to get permit nonce for %s: %w", approval.token.Hex(), err)
+    }
+    nonce := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+
+    sig, err := ec.walletMgr.SignERC2612Permit(&wallet.ERC2612Permit{
+        TokenName:    domain.Name,
+        TokenVersion: domain.Version,
+        Token:        approval.token,
+        Spender:      approval.spender,
+        Value:        approval.amount,
+        Nonce:        nonce,
+        Deadline:     deadline,
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return uniswapV3RouterABI.Pack("selfPermit", approval.token, approval.amount, deadline, sig.V, sig.R, sig.S)
+}
+
+func approvalMethod(approval *TokenApproval) string {
+    if approval != nil {
+        return ApprovalMethodApprove
+    }
+    return ApprovalMethodNone
+}
@@ -0,0 +1,169 @@
+//Uniswap Permit2 签名授权，swap 通过 Universal Router 执行
+package ethereum
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+
+    "github.com/ethereum/go-ethereum/accounts/abi"
+    "github.com/ethereum/go-ethereum/accounts/abi/bind"
+    "github.com/ethereum/go-ethereum/common"
+
+    "github.com/your-username/ethereum-trading-mcp/internal/wallet"
+)
+
+const defaultPermit2Address = "0x000000000022D473030F116dDEE9F6B43aC78BA3"
+
+// Universal Router 的命令
+const (
+    urV3SwapExactIn  byte = 0x00
+    urV3SwapExactOut byte = 0x01
+    urV2SwapExactIn  byte = 0x08
+    urV2SwapExactOut byte = 0x09
+    urPermit2Permit  byte = 0x0a
+    urUnwrapWETH     byte = 0x0c
+)
+
+// 作为接收地址时表示 Universal Router 自身，输出 ETH 时先把 WETH 留在 router 再 unwrap
+var urAddressThis = common.HexToAddress("0x0000000000000000000000000000000000000002")
+
+// Universal Router 的一条命令和对应的输入
+type urCommand struct {
+    command byte
+    input   []byte
+}
+
+func (ec *EthereumClient) permit2Address() common.Address {
+    if ec.config.Permit2Address != "" {
+        return common.HexToAddress(ec.config.Permit2Address)
+    }
+    return common.HexToAddress(defaultPermit2Address)
+}
+
+// 签名 Permit2 PermitSingle 并编码为 PERMIT2_PERMIT 命令，spender 为 Universal Router。
+// Permit2 通过 transferFrom 拉取代币，只有钱包已经授权过 Permit2 的代币才能使用
+func (ec *EthereumClient) buildPermit2Permit(ctx context.Context, approval *TokenApproval, deadline *big.Int) (*urCommand, error) {
+    if ec.config.UniversalRouter == "" {
+        return nil, fmt.Errorf("universal router not configured")
+    }
+    router := common.HexToAddress(ec.config.UniversalRouter)
+    permit2 := ec.permit2Address()
+    owner := ec.walletMgr.GetAddress()
+
+    allowance, err := ec.tokenAllowance(ctx, approval.token, owner, permit2)
+    if err != nil {
+        return nil, err
+    }
+    if allowance.Cmp(approval.amount) < 0 {
+        return nil, fmt.Errorf("%s is not approved for Permit2", approval.token.Hex())
+    }
+
+    contract := bind.NewBoundContract(permit2, permit2ABI, ec.caller, nil, nil)
+    var out []interface{}
+    if err := contract.Call(&bind.CallOpts{Context: ctx}, &out, "allowance", owner, approval.token, router); err != nil {
+        return nil, fmt.Errorf("failed to get Permit2 nonce for %s: %w", approval.token.Hex(), err)
+    }
+    nonce := abi.ConvertType(out[2], new(big.Int)).(*big.Int)
+
+    // 授权在 swap 截止时间过期，精确输出没用完的额度不会留给 router
+    permit := permit2PermitSingle{
+        Details: permit2Details{
+            Token:      approval.token,
+            Amount:     approval.amount,
+            Expiration: deadline,
+            Nonce:      nonce,
+        },
+        Spender:     router,
+        SigDeadline: deadline,
+    }
+    sig, err := ec.walletMgr.SignPermit2(&wallet.Permit2Single{
+        Permit2:     permit2,
+        Token:       permit.Details.Token,
+        Spender:     permit.Spender,
+        Amount:      permit.Details.Amount,
+        Expiration:  permit.Details.Expiration,
+        Nonce:       permit.Details.Nonce,
+        SigDeadline: permit.SigDeadline,
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    input, err := universalRouterInputsABI.Methods["permit2Permit"].Inputs.Pack(permit, sig.Signature)
+    if err != nil {
+        return nil, err
+    }
+    return &urCommand{command: urPermit2Permit, input: input}, nil
+}
+
+// V2 swap 的 Universal Router 命令，代币由 Permit2 从钱包拉取
+func v2URSwap(pair *swapPair, limits *swapLimits, path []common.Address, recipient common.Address) (*urCommand, error) {
+    if pair.ExactOutput {
+        input, err := universalRouterInputsABI.Methods["v2SwapExactOut"].Inputs.Pack(recipient, limits.AmountOut, limits.AmountInMax, path, true)
+        return &urCommand{command: urV2SwapExactOut, input: input}, err
+    }
+    input, err := universalRouterInputsABI.Methods["v2SwapExactIn"].Inputs.Pack(recipient, limits.AmountIn, limits.AmountOutMin, path, true)
+    return &urCommand{command: urV2SwapExactIn, input: input}, err
+}
+
+// V3 swap 的 Universal Router 命令，精确输出的路径从输出代币开始编码
+func v3URSwap(route *v3Route, limits *swapLimits, recipient common.Address) (*urCommand, error) {
+    if route.ExactOutput {
+        path := encodeV3Path([]common.Address{route.Path[1], route.Path[0]}, route.Fees())
+        input, err := universalRouterInputsABI.Methods["v3SwapExactOut"].Inputs.Pack(recipient, limits.AmountOut, limits.AmountInMax, path, true)
+        return &urCommand{command: urV3SwapExactOut, input: input}, err
+    }
+    path := encodeV3Path(route.Path, route.Fees())
+    input, err := universalRouterInputsABI.Methods["v3SwapExactIn"].Inputs.Pack(recipient, limits.AmountIn, limits.AmountOutMin, path, true)
+    return &urCommand{command: urV3SwapExactIn, input: input}, err
+}
+
+// 依次执行 permit、swap，输出为 ETH 时最后 unwrap 给接收地址
+func (ec *EthereumClient) buildURSwapData(pair *swapPair, limits *swapLimits, permit *urCommand, swap func(recipient common.Address) (*urCommand, error)) ([]byte, error) {
+    toETH := pair.To == common.HexToAddress(ec.config.WETHAddress)
+    recipient := pair.Recipient
+    if toETH {
+        recipient = urAddressThis
+    }
+    swapCmd, err := swap(recipient)
+    if err != nil {
+        return nil, err
+    }
+
+    cmds := []*urCommand{permit, swapCmd}
+    if toETH {
+        input, err := universalRouterInputsABI.Methods["unwrapWETH"].Inputs.Pack(pair.Recipient, limits.AmountOutMin)
+        if err != nil {
+            return nil, err
+        }
+        cmds = append(cmds, &urCommand{command: urUnwrapWETH, input: input})
+    }
+
+    commands := make([]byte, 0, len(cmds))
+    inputs := make([][]byte, 0, len(cmds))
+    for _, cmd := range cmds {
+        commands = append(commands, cmd.command)
+        inputs = append(inputs, cmd.input)
+    }
+    return universalRouterABI.Pack("execute", commands, inputs, pair.deadlineUnix())
+}
+
+// 签名 Permit2 后构建 Universal Router 交易并预估 gas，预估失败时调用方改用 approve
+func (ec *EthereumClient) buildPermit2Swap(ctx context.Context, pair *swapPair, limits *swapLimits, approval *TokenApproval, swap func(recipient common.Address) (*urCommand, error)) (*swapTransaction, uint64, error) {
+    permit, err := ec.buildPermit2Permit(ctx, approval, pair.deadlineUnix())
+    if err != nil {
+        return nil, 0, err
+    }
+    data, err := ec.buildURSwapData(pair, limits, permit, swap)
+    if err != nil {
+        return nil, 0, err
+    }
+
+    router := common.HexToAddress(ec.config.UniversalRouter)
+    gasEstimate, err := ec.EstimateGas(ctx, ec.walletMgr.GetAddress(), &router, big.NewInt(0), data)
+    if err != nil {
+        return nil, 0, fmt.Errorf("permit2 swap reverted: %w", err)
+    }
+    return &swapTransaction{To: router, Data: data, Value: big.NewInt(0)}, gasEstimate, nil
+}
@@ -0,0 +1,150 @@
+package ethereum
+
+import (
+    "context"
+    "math/big"
+    "testing"
+    "time"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/common/hexutil"
+    "github.com/ethereum/go-ethereum/crypto"
+    "github.com/stretchr/testify/assert"
+    "go.uber.org/zap"
+
+    "github.com/your-username/ethereum-trading-mcp/internal/wallet"
+)
+
+var testUniversalRouter = common.HexToAddress("0x66a9893cC07D91D95644AEDD05D03f95e1dBA8Af")
+
+// 解码 execute 调用，返回命令和每条命令的输入
+func decodeURExecute(t *testing.T, data []byte) ([]byte, [][]byte) {
+    method, err := universalRouterABI.MethodById(data[:4])
+    assert.NoError(t, err)
+    assert.Equal(t, "execute", method.Name)
+    args, err := method.Inputs.Unpack(data[4:])
+    assert.NoError(t, err)
+    assert.Equal(t, big.NewInt(1700000000), args[2])
+    return args[0].([]byte), args[1].([][]byte)
+}
+
+func unpackURInput(t *testing.T, name string, input []byte) []interface{} {
+    args, err := universalRouterInputsABI.Methods[name].Inputs.Unpack(input)
+    assert.NoError(t, err)
+    return args
+}
+
+func TestBuildURSwapData(t *testing.T) {
+    ec := newRouteTestClient(nil)
+    recipient := common.HexToAddress("0x742d35Cc6634C0532925a3b8D7a2a5c4A7A6A5a5")
+    permit := &urCommand{command: urPermit2Permit, input: []byte{0x01}}
+    limits := &swapLimits{
+        AmountIn:     big.NewInt(1000),
+        AmountOut:    big.NewInt(2000),
+        AmountInMax:  big.NewInt(1010),
+        AmountOutMin: big.NewInt(1980),
+    }
+
+    // 输出为 ETH: swap 的 WETH 留在 router，再 unwrap 给接收地址
+    pair := &swapPair{From: testUSDC, To: testWETH, Recipient: recipient, Deadline: time.Unix(1700000000, 0)}
+    data, err := ec.buildURSwapData(pair, limits, permit, func(to common.Address) (*urCommand, error) {
+        return v2URSwap(pair, limits, []common.Address{testUSDC, testWETH}, to)
+    })
+    assert.NoError(t, err)
+    commands, inputs := decodeURExecute(t, data)
+    assert.Equal(t, []byte{urPermit2Permit, urV2SwapExactIn, urUnwrapWETH}, commands)
+    assert.Equal(t, permit.input, inputs[0])
+    swap := unpackURInput(t, "v2SwapExactIn", inputs[1])
+    assert.Equal(t, urAddressThis, swap[0])
+    assert.Equal(t, limits.AmountIn, swap[1])
+    assert.Equal(t, limits.AmountOutMin, swap[2])
+    assert.Equal(t, []common.Address{testUSDC, testWETH}, swap[3])
+    assert.Equal(t, true, swap[4])
+    unwrap := unpackURInput(t, "unwrapWETH", inputs[2])
+    assert.Equal(t, recipient, unwrap[0])
+    assert.Equal(t, limits.AmountOutMin, unwrap[1])
+
+    // V3 精确输出的路径从输出代币开始
+    pair = &swapPair{From: testUSDC, To: testDAI, Recipient: recipient, ExactOutput: true, Deadline: time.Unix(1700000000, 0)}
+    route := &v3Route{Path: []common.Address{testUSDC, testDAI}, Quotes: []*v3Quote{{Fee: 100}}, ExactOutput: true}
+    data, err = ec.buildURSwapData(pair, limits, permit, func(to common.Address) (*urCommand, error) {
+        return v3URSwap(route, limits, to)
+    })
+    assert.NoError(t, err)
+    commands, inputs = decodeURExecute(t, data)
+    assert.Equal(t, []byte{urPermit2Permit, urV3SwapExactOut}, commands)
+    swap = unpackURInput(t, "v3SwapExactOut", inputs[1])
+    assert.Equal(t, recipient, swap[0])
+    assert.Equal(t, limits.AmountOut, swap[1])
+    assert.Equal(t, limits.AmountInMax, swap[2])
+    assert.Equal(t, encodeV3Path([]common.Address{testDAI, testUSDC}, []uint32{100}), swap[3])
+}
+
+func TestBuildPermit2Permit(t *testing.T) {
+    key, err := crypto.GenerateKey()
+    assert.NoError(t, err)
+    walletMgr, err := wallet.NewWalletManager(&wallet.WalletConfig{
+        PrivateKey:  hexutil.Encode(crypto.FromECDSA(key))[2:],
+        RPCEndpoint: "http://127.0.0.1:1",
+        ChainID:     1,
+    }, zap.NewNop())
+    assert.NoError(t, err)
+    owner := walletMgr.GetAddress()
+    permit2 := common.HexToAddress(defaultPermit2Address)
+
+    permit2Allowance := big.NewInt(0)
+    caller := fakeContracts{
+        testUSDC: {abi: erc20ABI, methods: map[string]fakeMethod{
+            "allowance": func(args []interface{}) ([]interface{}, error) {
+                assert.Equal(t, owner, args[0])
+                assert.Equal(t, permit2, args[1])
+                return []interface{}{permit2Allowance}, nil
+            },
+        }},
+        permit2: {abi: permit2ABI, methods: map[string]fakeMethod{
+            "allowance": func(args []interface{}) ([]interface{}, error) {
+                assert.Equal(t, testUniversalRouter, args[2])
+                return []interface{}{big.NewInt(0), big.NewInt(0), big.NewInt(5)}, nil
+            },
+        }},
+    }
+    ec := newRouteTestClient(caller)
+    ec.walletMgr = walletMgr
+    ec.config.UniversalRouter = testUniversalRouter.Hex()
+
+    approval := &TokenApproval{token: testUSDC, spender: testV3Router, amount: big.NewInt(1000000)}
+    deadline := big.NewInt(1700000000)
+
+    // 钱包没有授权 Permit2 时不能使用
+    _, err = ec.buildPermit2Permit(context.Background(), approval, deadline)
+    assert.Error(t, err)
+
+    permit2Allowance = new(big.Int).Lsh(big.NewInt(1), 160)
+    cmd, err := ec.buildPermit2Permit(context.Background(), approval, deadline)
+    assert.NoError(t, err)
+    assert.Equal(t, urPermit2Permit, cmd.command)
+
+    args := unpackURInput(t, "permit2Permit", cmd.input)
+    permit := args[0].(struct {
+        Details struct {
+            Token      common.Address `json:"token"`
+            Amount     *big.Int       `json:"amount"`
+            Expiration *big.Int       `json:"expiration"`
+            Nonce      *big.Int       `json:"nonce"`
+        } `json:"details"`
+        Spender     common.Address `json:"spender"`
+        SigDeadline *big.Int       `json:"sigDeadline"`
+    })
+    assert.Equal(t, testUSDC, permit.Details.Token)
+    assert.Equal(t, approval.amount, permit.Details.Amount)
+    assert.Equal(t, deadline, permit.Details.Expiration)
+    assert.Equal(t, big.NewInt(5), permit.Details.Nonce)
+    assert.Equal(t, testUniversalRouter, permit.Spender)
+    assert.Equal(t, deadline, permit.SigDeadline)
+    assert.Len(t, args[1], 65)
+
+    // 没有配置 Universal Router 时不使用 Permit2
+    ec.config.UniversalRouter = ""
+    _, err = ec.buildPermit2Permit(context.Background(), approval, deadline)
+    assert.Error(t, err)
+}
@@ -0,0 +1,282 @@
+//钱包持仓和估值
+package ethereum
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/ethereum/go-ethereum/accounts/abi/bind"
+    "github.com/ethereum/go-ethereum/common"
+    "go.uber.org/zap"
+
+    "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
+)
+
+type PortfolioRequest struct {
+    Address string `json:"address"`
+    // 代币符号或合约地址，为空时使用配置的 watchlist
+    Tokens []string `json:"tokens,omitempty"`
+}
+
+type PortfolioHolding struct {
+    Symbol       string           `json:"symbol"`
+    Name         *string          `json:"name,omitempty"`
+    TokenAddress *string          `json:"token_address,omitempty"`
+    Balance      decimal.Decimal  `json:"balance"`
+    Decimals     int              `json:"decimals"`
+    PriceUSD     *decimal.Decimal `json:"price_usd,omitempty"`
+    PriceETH     *decimal.Decimal `json:"price_eth,omitempty"`
+    ValueUSD     *decimal.Decimal `json:"value_usd,omitempty"`
+    ValueETH     *decimal.Decimal `json:"value_eth,omitempty"`
+    // 占有价格持仓总市值 (USD) 的百分比
+    Allocation *decimal.Decimal `json:"allocation_percent,omitempty"`
+    PriceError *string          `json:"price_error,omitempty"`
+}
+
+type PortfolioResponse struct {
+    Address     string             `json:"address"`
+    AddressName *string            `json:"address_name,omitempty"`
+    Holdings    []PortfolioHolding `json:"holdings"`
+    // 没有价格的持仓不计入总额和占比
+    TotalUSD decimal.Decimal `json:"total_usd"`
+    TotalETH decimal.Decimal `json:"total_eth"`
+    // 无法识别或读取失败的代币及原因
+    TokenErrors map[string]string `json:"token_errors,omitempty"`
+    Timestamp   time.Time         `json:"timestamp"`
+    Success     bool              `json:"success"`
+    Error       *string           `json:"error,omitempty"`
+}
+
+// ETH 加上代币集合中余额不为 0 的 ERC20，按 USD 市值从高到低排列
+func (ec *EthereumClient) GetPortfolio(ctx context.Context, req *PortfolioRequest) (*PortfolioResponse, error) {
+    resp := &PortfolioResponse{
+        Address:   req.Address,
+        Timestamp: time.Now(),
+    }
+
+    owner, err := ec.ResolveAddress(ctx, req.Address)
+    if err != nil {
+        resp.Error = stringPtr(err.Error())
+        return resp, nil
+    }
+    resp.Address = owner.Hex()
+    resp.AddressName = ec.addressName(ctx, req.Address, owner)
+
+    identifiers := req.Tokens
+    if len(identifiers) == 0 {
+        identifiers = ec.config.Watchlist
+    }
+    tokens, symbols, tokenErrors := ec.resolvePortfolioTokens(ctx, identifiers)
+
+    ethBalance, err := ec.client.BalanceAt(ctx, owner, nil)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get ETH balance: %w", err)
+    }
+    holdings := []PortfolioHolding{{
+        Symbol:   "ETH",
+        Name:     stringPtr("Ethereum"),
+        Balance:  decimal.FromWei(ethBalance),
+        Decimals: 18,
+    }}
+
+    if len(tokens) > 0 {
+        infos, err := ec.erc20Infos(&bind.CallOpts{Context: ctx}, owner, tokens)
+        if err != nil {
+            return nil, err
+        }
+        for i, info := range infos {
+            if info.Err != nil {
+                tokenErrors[info.Token.Hex()] = info.Err.Error()
+                continue
+            }
+            if info.Balance.Sign() == 0 {
+                continue
+            }
+
+            // 优先使用调用方给出的符号查询价格，传入地址时使用链上的 symbol
+            symbol := symbols[i]
+            if symbol == "" && info.Symbol != nil {
+                symbol = *info.Symbol
+            }
+            holdings = append(holdings, PortfolioHolding{
+                Symbol:       symbol,
+                Name:         info.Name,
+                TokenAddress: stringPtr(info.Token.Hex()),
+                Balance:      decimal.FormatBalance(info.Balance, info.Decimals),
+                Decimals:     info.Decimals,
+            })
+        }
+    }
+
+    ec.pricePortfolioHoldings(ctx, holdings)
+    resp.TotalUSD, resp.TotalETH = setAllocations(holdings)
+
+    sort.SliceStable(holdings, func(i, j int) bool {
+        return holdingValue(holdings[i]).GreaterThan(holdingValue(holdings[j]))
+    })
+
+    resp.Holdings = holdings
+    if len(tokenErrors) > 0 {
+        resp.TokenErrors = tokenErrors
+    }
+    resp.Success = true
+    return resp, nil
+}
+
+// 代币标识转换为地址并去重，ETH 总是包含在结果中因此跳过；传入符号时同时返回符号用于查询价格
+func (ec *EthereumClient) resolvePortfolioTokens(ctx context.Context, identifiers []string) ([]common.Address, []string, map[string]string) {
+    var tokens []common.Address
+    var symbols []string
+    tokenErrors := make(map[string]string)
+    seen := make(map[common.Address]bool)
+
+    for _, identifier := range identifiers {
+        identifier = strings.TrimSpace(identifier)
+        if identifier == "" || strings.EqualFold(identifier, "ETH") {
+            continue
+        }
+
+        var addr common.Address
+        var symbol string
+        switch {
+        case common.IsHexAddress(identifier):
+            addr = common.HexToAddress(identifier)
+        case isENSName(identifier):
+            resolved, err := ec.resolveENSName(ctx, identifier)
+            if err != nil {
+                tokenErrors[identifier] = err.Error()
+                continue
+            }
+            addr = resolved
+        default:
+            symbol = strings.ToUpper(identifier)
+            addr = getTokenAddressBySymbol(symbol)
+            if addr == (common.Address{}) {
+                tokenErrors[identifier] = "unknown token symbol"
+                continue
+            }
+        }
+
+        if seen[addr] {
+            continue
+        }
+        seen[addr] = true
+        tokens = append(tokens, addr)
+        symbols = append(symbols, symbol)
+    }
+    return tokens, symbols, tokenErrors
+}
+
+// 所有持仓的价格最多用两次请求查询: 已知代币按 CoinGecko id，其余代币按合约地址
+func (ec *EthereumClient) pricePortfolioHoldings(ctx context.Context, holdings []PortfolioHolding) {
+    var ids []string
+    var addresses []common.Address
+    for _, holding := range holdings {
+        if id, ok := portfolioCoinGeckoID(holding); ok {
+            ids = append(ids, id)
+        } else if holding.TokenAddress != nil {
+            addresses = append(addresses, common.HexToAddress(*holding.TokenAddress))
+        }
+    }
+
+    var byID, byAddress map[string]map[string]float64
+    var idErr, addressErr error
+    if len(ids) > 0 {
+        byID, idErr = ec.prices.simplePrice(ctx, ids)
+    }
+    if len(addresses) > 0 {
+        platform, ok := coinGeckoPlatforms[ec.config.ChainID]
+        if ok {
+            byAddress, addressErr = ec.prices.tokenPrice(ctx, platform, addresses)
+        } else {
+            addressErr = fmt.Errorf("no CoinGecko platform for chain %d", ec.config.ChainID)
+        }
+    }
+
+    for i := range holdings {
+        holding := &holdings[i]
+        var coinData map[string]float64
+        var found bool
+        err := idErr
+        if id, ok := portfolioCoinGeckoID(*holding); ok {
+            coinData, found = byID[id]
+        } else if holding.TokenAddress != nil {
+            coinData, found = byAddress[strings.ToLower(*holding.TokenAddress)]
+            err = addressErr
+        }
+        if err == nil && !found {
+            err = fmt.Errorf("no CoinGecko price for %s", holding.Symbol)
+        }
+        if err == nil {
+            err = setHoldingPrice(holding, coinData)
+        }
+        if err != nil {
+            ec.logger.Debug("Failed to price portfolio holding",
+                zap.String("symbol", holding.Symbol),
+                zap.Error(err),
+            )
+            holding.PriceError = stringPtr(err.Error())
+        }
+    }
+}
+
+// ETH 和符号表中的代币按 CoinGecko id 查询；链上 symbol 相同但地址不同的代币不能按 id 定价
+func portfolioCoinGeckoID(holding PortfolioHolding) (string, bool) {
+    id, ok := coinGeckoIDs[holding.Symbol]
+    if !ok {
+        return "", false
+    }
+    if holding.TokenAddress == nil {
+        return id, true
+    }
+    return id, getTokenAddressBySymbol(holding.Symbol) == common.HexToAddress(*holding.TokenAddress)
+}
+
+func setHoldingPrice(holding *PortfolioHolding, coinData map[string]float64) error {
+    priceUSD, priceETH, err := coinGeckoPrices(holding.Symbol, coinData)
+    if err != nil {
+        return err
+    }
+    valueUSD := holding.Balance.Mul(priceUSD)
+    valueETH := holding.Balance.Mul(priceETH)
+    holding.PriceUSD = &priceUSD
+    holding.PriceETH = &priceETH
+    holding.ValueUSD = &valueUSD
+    holding.ValueETH = &valueETH
+    return nil
+}
+
+// 汇总有价格的持仓市值并计算各自的占比
+func setAllocations(holdings []PortfolioHolding) (decimal.Decimal, decimal.Decimal) {
+    totalUSD := decimal.Zero
+    totalETH := decimal.Zero
+    for _, holding := range holdings {
+        if holding.ValueUSD == nil {
+            continue
+        }
+        totalUSD = totalUSD.Add(*holding.ValueUSD)
+        totalETH = totalETH.Add(*holding.ValueETH)
+    }
+    if totalUSD.IsZero() {
+        return totalUSD, totalETH
+    }
+
+    for i := range holdings {
+        if holdings[i].ValueUSD == nil {
+            continue
+        }
+        allocation := holdings[i].ValueUSD.DivRound(totalUSD, 6).Mul(decimal.NewFromInt(100)).Round(2)
+        holdings[i].Allocation = &allocation
+    }
+    return totalUSD, totalETH
+}
+
+func holdingValue(holding PortfolioHolding) decimal.Decimal {
+    if holding.ValueUSD == nil {
+        return decimal.NewFromInt(-1)
+    }
+    return *holding.ValueUSD
+}
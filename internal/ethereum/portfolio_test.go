@@ -0,0 +1,101 @@
+package ethereum
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/stretchr/testify/assert"
+    "go.uber.org/zap"
+
+    "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
+)
+
+func TestSetAllocations(t *testing.T) {
+    value := func(usd, eth int64) (*decimal.Decimal, *decimal.Decimal) {
+        u, e := decimal.NewFromInt(usd), decimal.NewFromInt(eth)
+        return &u, &e
+    }
+
+    holdings := make([]PortfolioHolding, 3)
+    holdings[0].ValueUSD, holdings[0].ValueETH = value(750, 3)
+    holdings[1].ValueUSD, holdings[1].ValueETH = value(250, 1)
+    // 没有价格的持仓不计入总额
+    holdings[2].Balance = decimal.NewFromInt(10)
+
+    totalUSD, totalETH := setAllocations(holdings)
+    assert.True(t, totalUSD.Equal(decimal.NewFromInt(1000)))
+    assert.True(t, totalETH.Equal(decimal.NewFromInt(4)))
+    assert.Equal(t, "75.00", holdings[0].Allocation.StringFixed(2))
+    assert.Equal(t, "25.00", holdings[1].Allocation.StringFixed(2))
+    assert.Nil(t, holdings[2].Allocation)
+}
+
+func TestResolvePortfolioTokens(t *testing.T) {
+    ec := &EthereumClient{}
+    usdc := "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+
+    tokens, symbols, tokenErrors := ec.resolvePortfolioTokens(context.Background(), []string{"ETH", "usdc", usdc, "DAI", "NOPE"})
+
+    // ETH 单独查询，USDC 按地址去重
+    assert.Equal(t, []common.Address{
+        common.HexToAddress(usdc),
+        common.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F"),
+    }, tokens)
+    assert.Equal(t, []string{"USDC", "DAI"}, symbols)
+    assert.Contains(t, tokenErrors, "NOPE")
+}
+
+func TestPricePortfolioHoldings_Batched(t *testing.T) {
+    weth := "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+    usdc := "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+    fakeUSDC := "0x1111111111111111111111111111111111111111"
+
+    var requests []string
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requests = append(requests, r.URL.Path)
+        var body map[string]map[string]float64
+        switch r.URL.Path {
+        case "/simple/price":
+            assert.Equal(t, "ethereum,usd-coin", r.URL.Query().Get("ids"))
+            body = map[string]map[string]float64{
+                "ethereum": {"usd": 3000, "eth": 1},
+                "usd-coin": {"usd": 1, "eth": 0.0003},
+            }
+        case "/simple/token_price/ethereum":
+            assert.Equal(t, "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2,"+fakeUSDC, r.URL.Query().Get("contract_addresses"))
+            body = map[string]map[string]float64{
+                "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2": {"usd": 3001, "eth": 1},
+            }
+        default:
+            w.WriteHeader(http.StatusNotFound)
+            return
+        }
+        assert.NoError(t, json.NewEncoder(w).Encode(body))
+    }))
+    defer server.Close()
+
+    prices := newCoinGeckoClient()
+    prices.baseURL = server.URL
+    ec := &EthereumClient{logger: zap.NewNop(), config: &EthereumConfig{ChainID: 1}, prices: prices}
+
+    holdings := []PortfolioHolding{
+        {Symbol: "ETH", Balance: decimal.NewFromInt(2)},
+        {Symbol: "USDC", TokenAddress: &usdc, Balance: decimal.NewFromInt(100)},
+        // 链上 symbol 不在符号表中，按合约地址查询
+        {Symbol: "WETH", TokenAddress: &weth, Balance: decimal.NewFromInt(1)},
+        // symbol 冒用 USDC 的代币不能使用 USDC 的价格
+        {Symbol: "USDC", TokenAddress: &fakeUSDC, Balance: decimal.NewFromInt(5)},
+    }
+    ec.pricePortfolioHoldings(context.Background(), holdings)
+
+    assert.ElementsMatch(t, []string{"/simple/price", "/simple/token_price/ethereum"}, requests)
+    assert.True(t, decimal.NewFromInt(6000).Equal(*holdings[0].ValueUSD))
+    assert.True(t, decimal.NewFromInt(100).Equal(*holdings[1].ValueUSD))
+    assert.True(t, decimal.NewFromInt(3001).Equal(*holdings[2].ValueUSD))
+    assert.Nil(t, holdings[3].ValueUSD)
+    assert.Contains(t, *holdings[3].PriceError, "no CoinGecko price")
+}
@@ -7,6 +7,8 @@ import (
     "fmt"
     "io"
     "net/http"
+    "net/url"
+    "strings"
     "time"
 
     "github.com/ethereum/go-ethereum/common"
@@ -39,15 +41,30 @@ func (ec *EthereumClient) GetTokenPrice(ctx context.Context, tokenIdentifier str
     if tokenIdentifier == "ETH" || tokenIdentifier == "0x0000000000000000000000000000000000000000" {
         tokenAddress = common.HexToAddress("0x0000000000000000000000000000000000000000")
         symbol = "ETH"
-    } else if common.IsHexAddress(tokenIdentifier) {
-        tokenAddress = common.HexToAddress(tokenIdentifier)
+    } else if common.IsHexAddress(tokenIdentifier) || isENSName(tokenIdentifier) {
+        // 和 swap 的代币参数一样接受 ENS 名称
+        addr, err := ec.ResolveAddress(ctx, tokenIdentifier)
+        if err != nil {
+            return nil, fmt.Errorf("invalid token: %w", err)
+        }
+        tokenAddress = addr
         symbol = "UNKNOWN"
+        if known, ok := getTokenSymbolByAddress(addr); ok {
+            symbol = known
+        }
     } else {
         symbol = tokenIdentifier
         tokenAddress = getTokenAddressBySymbol(symbol)
     }
 
-    priceUSD, priceETH, err := ec.fetchPriceFromCoinGecko(ctx, symbol)
+    // 不在符号表中的合约按地址查询价格
+    var priceUSD, priceETH decimal.Decimal
+    var err error
+    if symbol == "UNKNOWN" {
+        priceUSD, priceETH, err = ec.fetchTokenPriceByAddress(ctx, tokenAddress)
+    } else {
+        priceUSD, priceETH, err = ec.fetchPriceFromCoinGecko(ctx, symbol)
+    }
     if err != nil {
         return nil, fmt.Errorf("failed to fetch price: %w", err)
     }
@@ -66,78 +83,174 @@ func (ec *EthereumClient) fetchPriceFromCoinGecko(ctx context.Context, symbol st
     // 将符号转换为CoinGecko 的id
     coinID := getCoinGeckoID(symbol)
 
-    url := fmt.Sprintf("https://api.coingecko.com/api/v3/simple/price?ids=%s&vs_currencies=usd,eth", coinID)
-
-    req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+    result, err := ec.prices.simplePrice(ctx, []string{coinID})
     if err != nil {
         return decimal.Zero, decimal.Zero, err
     }
 
-    client := &http.Client{Timeout: 10 * time.Second}
-    resp, err := client.Do(req)
-    if err != nil {
-        return decimal.Zero, decimal.Zero, err
+    coinData, exists := result[coinID]
+    if !exists {
+        return decimal.Zero, decimal.Zero, fmt.Errorf("coin %s not found", symbol)
     }
-    defer resp.Body.Close()
+    return coinGeckoPrices(symbol, coinData)
+}
 
-    body, err := io.ReadAll(resp.Body)
-    if err != nil {
-        return decimal.Zero, decimal.Zero, err
+func (ec *EthereumClient) fetchTokenPriceByAddress(ctx context.Context, token common.Address) (decimal.Decimal, decimal.Decimal, error) {
+    platform, ok := coinGeckoPlatforms[ec.config.ChainID]
+    if !ok {
+        return decimal.Zero, decimal.Zero, fmt.Errorf("no CoinGecko platform for chain %d", ec.config.ChainID)
     }
-
-    var result map[string]map[string]float64
-    if err := json.Unmarshal(body, &result); err != nil {
+    result, err := ec.prices.tokenPrice(ctx, platform, []common.Address{token})
+    if err != nil {
         return decimal.Zero, decimal.Zero, err
     }
-
-    coinData, exists := result[coinID]
+    coinData, exists := result[strings.ToLower(token.Hex())]
     if !exists {
-        return decimal.Zero, decimal.Zero, fmt.Errorf("coin %s not found", symbol)
+        return decimal.Zero, decimal.Zero, fmt.Errorf("token %s not found", token.Hex())
     }
+    return coinGeckoPrices(token.Hex(), coinData)
+}
 
+// 返回 USD 和 ETH 计价的价格
+func coinGeckoPrices(name string, coinData map[string]float64) (decimal.Decimal, decimal.Decimal, error) {
     usdPrice, usdExists := coinData["usd"]
     ethPrice, ethExists := coinData["eth"]
 
     if !usdExists || !ethExists {
-        return decimal.Zero, decimal.Zero, fmt.Errorf("price data incomplete for %s", symbol)
+        return decimal.Zero, decimal.Zero, fmt.Errorf("price data incomplete for %s", name)
     }
 
     return decimal.NewFromFloat(usdPrice), decimal.NewFromFloat(ethPrice), nil
 }
 
+const coinGeckoAPIURL = "https://api.coingecko.com/api/v3"
+
+// CoinGecko 的 simple 接口，一次请求可以查询多个币种或合约地址
+type coinGeckoClient struct {
+    baseURL    string
+    httpClient *http.Client
+}
+
+func newCoinGeckoClient() *coinGeckoClient {
+    return &coinGeckoClient{
+        baseURL:    coinGeckoAPIURL,
+        httpClient: &http.Client{Timeout: 10 * time.Second},
+    }
+}
+
+// 按 CoinGecko id 批量查询，结果以 id 为键
+func (c *coinGeckoClient) simplePrice(ctx context.Context, ids []string) (map[string]map[string]float64, error) {
+    query := url.Values{}
+    query.Set("ids", strings.Join(ids, ","))
+    query.Set("vs_currencies", "usd,eth")
+    return c.get(ctx, "/simple/price", query)
+}
+
+// 按合约地址批量查询，结果以小写地址为键
+func (c *coinGeckoClient) tokenPrice(ctx context.Context, platform string, addresses []common.Address) (map[string]map[string]float64, error) {
+    hexAddresses := make([]string, len(addresses))
+    for i, addr := range addresses {
+        hexAddresses[i] = strings.ToLower(addr.Hex())
+    }
+    query := url.Values{}
+    query.Set("contract_addresses", strings.Join(hexAddresses, ","))
+    query.Set("vs_currencies", "usd,eth")
+    return c.get(ctx, "/simple/token_price/"+platform, query)
+}
+
+func (c *coinGeckoClient) get(ctx context.Context, path string, query url.Values) (map[string]map[string]float64, error) {
+    req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path+"?"+query.Encode(), nil)
+    if err != nil {
+        return nil, err
+    }
+
+    resp, err := c.httpClient.Do(req)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+
+    body, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, err
+    }
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("coingecko returned %s", resp.Status)
+    }
+
+    var result map[string]map[string]float64
+    if err := json.Unmarshal(body, &result); err != nil {
+        return nil, err
+    }
+    return result, nil
+}
+
+// 符号到 CoinGecko ID 的映射
+var coinGeckoIDs = map[string]string{
+    "ETH":  "ethereum",
+    "USDC": "usd-coin",
+    "USDT": "tether",
+    "DAI":  "dai",
+    "WBTC": "wrapped-bitcoin",
+    "UNI":  "uniswap",
+    "LINK": "chainlink",
+    "AAVE": "aave",
+    "BNB":  "binancecoin",
+    "POL":  "polygon-ecosystem-token",
+    "AVAX": "avalanche-2",
+    "XDAI": "xdai",
+}
+
+// 各链在 CoinGecko token_price 接口中的平台 id
+var coinGeckoPlatforms = map[int64]string{
+    1:     "ethereum",
+    10:    "optimistic-ethereum",
+    56:    "binance-smart-chain",
+    100:   "xdai",
+    137:   "polygon-pos",
+    8453:  "base",
+    42161: "arbitrum-one",
+    43114: "avalanche",
+}
+
 func getCoinGeckoID(symbol string) string {
-    // 符号到 CoinGecko ID 的映射
-    mapping := map[string]string{
-        "ETH":  "ethereum",
-        "USDC": "usd-coin",
-        "USDT": "tether",
-        "DAI":  "dai",
-        "WBTC": "wrapped-bitcoin",
-        "UNI":  "uniswap",
-        "LINK": "chainlink",
-        "AAVE": "aave",
-    }
-
-    if id, exists := mapping[symbol]; exists {
+    if id, exists := coinGeckoIDs[symbol]; exists {
         return id
     }
     return symbol // 回退到使用原始符号
 }
 
+// 符号到地址的映射（主网）
+var tokenAddresses = map[string]string{
+    "USDC": "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+    "USDT": "0xdAC17F958D2ee523a2206206994597C13D831ec7",
+    "DAI":  "0x6B175474E89094C44Da98b954EedeAC495271d0F",
+    "WBTC": "0x2260FAC5E5542a773Aa44fBCfeDf7C193bc2C599",
+    "UNI":  "0x1f9840a85d5aF5bf1D1762F925BDADdC4201F984",
+    "LINK": "0x514910771AF9Ca656af840dff83E8264EcF986CA",
+    "AAVE": "0x7Fc66500c84A76Ad7e9c93437bFc5Ac33E2DDaE9",
+}
+
 func getTokenAddressBySymbol(symbol string) common.Address {
-    // 符号到地址的映射（主网）
-    mapping := map[string]string{
-        "USDC": "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
-        "USDT": "0xdAC17F958D2ee523a2206206994597C13D831ec7",
-        "DAI":  "0x6B175474E89094C44Da98b954EedeAC495271d0F",
-        "WBTC": "0x2260FAC5E5542a773Aa44fBCfeDf7C193bc2C599",
-        "UNI":  "0x1f9840a85d5aF5bf1D1762F925BDADdC4201F984",
-        "LINK": "0x514910771AF9Ca656af840dff83E8264EcF986CA",
-        "AAVE": "0x7Fc66500c84A76Ad7e9c93437bFc5Ac33E2DDaE9",
-    }
-
-    if addr, exists := mapping[symbol]; exists {
+    if addr, exists := tokenAddresses[symbol]; exists {
         return common.HexToAddress(addr)
     }
     return common.HexToAddress("0x0000000000000000000000000000000000000000")
 }
+
+func getTokenSymbolByAddress(address common.Address) (string, bool) {
+    for symbol, addr := range tokenAddresses {
+        if common.HexToAddress(addr) == address {
+            return symbol, true
+        }
+    }
+    return "", false
+}
+
+// 符号表或价格源中已有的代币符号，不区分大小写
+func isKnownTokenSymbol(symbol string) bool {
+    symbol = strings.ToUpper(symbol)
+    _, isToken := tokenAddresses[symbol]
+    _, isCoin := coinGeckoIDs[symbol]
+    return isToken || isCoin
+}
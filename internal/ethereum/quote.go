@@ -0,0 +1,112 @@
+//swap 报价存储
+package ethereum
+
+import (
+    "crypto/rand"
+    "encoding/hex"
+    "fmt"
+    "sync"
+    "time"
+)
+
+const defaultQuoteTTL = 60 * time.Second
+
+// 服务端保存的 swap 报价，执行时只接受未过期的报价
+type StoredQuote struct {
+    ID          string
+    Request     SwapRequest
+    Response    *SwapResponse
+    BlockNumber uint64
+    CreatedAt   time.Time
+    ExpiresAt   time.Time
+}
+
+type quoteStore struct {
+    mu     sync.Mutex
+    ttl    time.Duration
+    quotes map[string]*StoredQuote
+}
+
+func newQuoteStore(ttl time.Duration) *quoteStore {
+    if ttl <= 0 {
+        ttl = defaultQuoteTTL
+    }
+    return &quoteStore{
+        ttl:    ttl,
+        quotes: make(map[string]*StoredQuote),
+    }
+}
+
+func (s *quoteStore) save(req *SwapRequest, resp *SwapResponse, blockNumber uint64) *StoredQuote {
+    now := time.Now()
+    quote := &StoredQuote{
+        ID:          newQuoteID(),
+        Request:     *req,
+        Response:    resp,
+        BlockNumber: blockNumber,
+        CreatedAt:   now,
+        ExpiresAt:   now.Add(s.ttl),
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    // 顺便清理过期的报价
+    for id, q := range s.quotes {
+        if now.After(q.ExpiresAt) {
+            delete(s.quotes, id)
+        }
+    }
+    s.quotes[quote.ID] = quote
+
+    return quote
+}
+
+func (s *quoteStore) get(id string) (*StoredQuote, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    quote, ok := s.quotes[id]
+    if !ok {
+        return nil, fmt.Errorf("unknown quote_id: %s", id)
+    }
+    if time.Now().After(quote.ExpiresAt) {
+        delete(s.quotes, id)
+        return nil, fmt.Errorf("quote %s expired at %s, please simulate again", id, quote.ExpiresAt.Format(time.RFC3339))
+    }
+    return quote, nil
+}
+
+// 取出并删除报价，查找和删除在同一把锁内完成，同一报价只能被一次执行取到
+func (s *quoteStore) take(id string) (*StoredQuote, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    quote, ok := s.quotes[id]
+    if !ok {
+        return nil, fmt.Errorf("unknown quote_id: %s", id)
+    }
+    delete(s.quotes, id)
+    if time.Now().After(quote.ExpiresAt) {
+        return nil, fmt.Errorf("quote %s expired at %s, please simulate again", id, quote.ExpiresAt.Format(time.RFC3339))
+    }
+    return quote, nil
+}
+
+// 交易广播前失败时放回报价，允许用同一 quote_id 重试
+func (s *quoteStore) restore(quote *StoredQuote) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if time.Now().After(quote.ExpiresAt) {
+        return
+    }
+    s.quotes[quote.ID] = quote
+}
+
+func newQuoteID() string {
+    b := make([]byte, 16)
+    if _, err := rand.Read(b); err != nil {
+        panic(err)
+    }
+    return hex.EncodeToString(b)
+}
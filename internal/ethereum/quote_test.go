@@ -0,0 +1,58 @@
+package ethereum
+
+import (
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestQuoteStore_TakeOnce(t *testing.T) {
+    store := newQuoteStore(time.Minute)
+    quote := store.save(&SwapRequest{FromToken: "ETH", ToToken: "USDC"}, &SwapResponse{Success: true}, 100)
+
+    // 并发执行同一个 quote_id，只有一次能取到报价
+    var wg sync.WaitGroup
+    var mu sync.Mutex
+    taken := 0
+    for i := 0; i < 8; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            if _, err := store.take(quote.ID); err == nil {
+                mu.Lock()
+                taken++
+                mu.Unlock()
+            }
+        }()
+    }
+    wg.Wait()
+    assert.Equal(t, 1, taken)
+
+    _, err := store.take(quote.ID)
+    assert.Error(t, err)
+
+    // 广播前失败放回后可以重试
+    store.restore(quote)
+    got, err := store.take(quote.ID)
+    assert.NoError(t, err)
+    assert.Equal(t, quote.ID, got.ID)
+}
+
+func TestQuoteStore_Expiry(t *testing.T) {
+    store := newQuoteStore(20 * time.Millisecond)
+    quote := store.save(&SwapRequest{FromToken: "ETH", ToToken: "USDC"}, &SwapResponse{Success: true}, 100)
+
+    _, err := store.get(quote.ID)
+    assert.NoError(t, err)
+
+    time.Sleep(30 * time.Millisecond)
+    _, err = store.get(quote.ID)
+    assert.Error(t, err)
+
+    // 过期的报价不会被放回
+    store.restore(quote)
+    _, err = store.take(quote.ID)
+    assert.Error(t, err)
+}
@@ -0,0 +1,342 @@
+//通过私有中继 (Flashbots 风格) 提交交易
+package ethereum
+
+import (
+    "bytes"
+    "context"
+    "crypto/ecdsa"
+    "encoding/json"
+    "fmt"
+    "io"
+    "math/big"
+    "net/http"
+    "sync/atomic"
+    "time"
+
+    "github.com/ethereum/go-ethereum/accounts"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/common/hexutil"
+    "github.com/ethereum/go-ethereum/core/types"
+    "github.com/ethereum/go-ethereum/crypto"
+    "go.uber.org/zap"
+)
+
+const (
+    // eth_sendBundle: 每出一个新区块提交一个只含该交易、以下一个区块为目标的 bundle
+    RelayModeBundle = "bundle"
+    // eth_sendPrivateTransaction: 中继在 maxBlockNumber 之前持续尝试打包
+    RelayModePrivate = "private"
+)
+
+// 交易的实际提交方式
+const (
+    SubmissionPublic = "public"
+    SubmissionRelay  = "relay"
+    // 中继拒绝后改为公开广播
+    SubmissionRelayFallback = "public_fallback"
+)
+
+const (
+    defaultRelayFallbackBlocks = 25
+    relayRequestTimeout        = 10 * time.Second
+    relaySignatureHeader       = "X-Flashbots-Signature"
+
+    // watcher 连续查询失败这么多次后放弃，释放持有的 nonce
+    relayWatchMaxFailures = 20
+    // 按主网出块时间估算回退窗口的时长，watcher 最多运行该时长的两倍
+    relayWatchBlockTime = 12 * time.Second
+)
+
+// 中继提交和公开广播用到的链上操作，ethclient.Client 实现了该接口
+type relayChain interface {
+    BlockNumber(ctx context.Context) (uint64, error)
+    NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+    SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+type relayClient struct {
+    url        string
+    mode       string
+    authKey    *ecdsa.PrivateKey
+    httpClient *http.Client
+    nextID     atomic.Uint64
+}
+
+type relayRequest struct {
+    JSONRPC string        `json:"jsonrpc"`
+    ID      uint64        `json:"id"`
+    Method  string        `json:"method"`
+    Params  []interface{} `json:"params"`
+}
+
+type relayResponse struct {
+    Result json.RawMessage `json:"result"`
+    Error  *struct {
+        Code    int    `json:"code"`
+        Message string `json:"message"`
+    } `json:"error"`
+}
+
+// authKey 只用于中继识别请求方的身份，不持有资金，为空时使用临时生成的密钥
+func newRelayClient(url, mode string, authKey *ecdsa.PrivateKey) (*relayClient, error) {
+    switch mode {
+    case "":
+        mode = RelayModePrivate
+    case RelayModeBundle, RelayModePrivate:
+    default:
+        return nil, fmt.Errorf("invalid relay mode %q, must be %q or %q", mode, RelayModeBundle, RelayModePrivate)
+    }
+
+    if authKey == nil {
+        key, err := crypto.GenerateKey()
+        if err != nil {
+            return nil, fmt.Errorf("failed to generate relay auth key: %w", err)
+        }
+        authKey = key
+    }
+
+    return &relayClient{
+        url:        url,
+        mode:       mode,
+        authKey:    authKey,
+        httpClient: &http.Client{Timeout: relayRequestTimeout},
+    }, nil
+}
+
+// bundle 模式下只提交以 targetBlock 为目标的 bundle，之后的区块由 watchRelayedTransaction 逐块提交；
+// 交易在 lastBlock 之后仍未上链时由调用方改为公开广播
+func (r *relayClient) submit(ctx context.Context, tx *types.Transaction, targetBlock, lastBlock uint64) error {
+    raw, err := tx.MarshalBinary()
+    if err != nil {
+        return fmt.Errorf("failed to encode transaction: %w", err)
+    }
+
+    if r.mode == RelayModeBundle {
+        _, err = r.sendBundle(ctx, [][]byte{raw}, targetBlock)
+        return err
+    }
+
+    _, err = r.sendPrivateTransaction(ctx, raw, lastBlock)
+    return err
+}
+
+func (r *relayClient) sendBundle(ctx context.Context, txs [][]byte, blockNumber uint64) (common.Hash, error) {
+    encoded := make([]string, len(txs))
+    for i, raw := range txs {
+        encoded[i] = hexutil.Encode(raw)
+    }
+    params := map[string]interface{}{
+        "txs":         encoded,
+        "blockNumber": hexutil.EncodeUint64(blockNumber),
+    }
+
+    var result struct {
+        BundleHash common.Hash `json:"bundleHash"`
+    }
+    if err := r.call(ctx, "eth_sendBundle", params, &result); err != nil {
+        return common.Hash{}, err
+    }
+    return result.BundleHash, nil
+}
+
+func (r *relayClient) sendPrivateTransaction(ctx context.Context, raw []byte, maxBlockNumber uint64) (common.Hash, error) {
+    params := map[string]interface{}{
+        "tx":             hexutil.Encode(raw),
+        "maxBlockNumber": hexutil.EncodeUint64(maxBlockNumber),
+    }
+
+    var hash common.Hash
+    if err := r.call(ctx, "eth_sendPrivateTransaction", params, &hash); err != nil {
+        return common.Hash{}, err
+    }
+    return hash, nil
+}
+
+func (r *relayClient) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+    body, err := json.Marshal(relayRequest{
+        JSONRPC: "2.0",
+        ID:      r.nextID.Add(1),
+        Method:  method,
+        Params:  []interface{}{params},
+    })
+    if err != nil {
+        return fmt.Errorf("failed to encode %s request: %w", method, err)
+    }
+
+    signature, err := r.sign(body)
+    if err != nil {
+        return err
+    }
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+    if err != nil {
+        return fmt.Errorf("failed to create relay request: %w", err)
+    }
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set(relaySignatureHeader, signature)
+
+    resp, err := r.httpClient.Do(req)
+    if err != nil {
+        return fmt.Errorf("relay %s request failed: %w", method, err)
+    }
+    defer resp.Body.Close()
+
+    respBody, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return fmt.Errorf("failed to read relay response: %w", err)
+    }
+    if resp.StatusCode != http.StatusOK {
+        return fmt.Errorf("relay %s returned status %d: %s", method, resp.StatusCode, string(respBody))
+    }
+
+    var rpcResp relayResponse
+    if err := json.Unmarshal(respBody, &rpcResp); err != nil {
+        return fmt.Errorf("failed to decode relay response: %w", err)
+    }
+    if rpcResp.Error != nil {
+        return fmt.Errorf("relay %s error %d: %s", method, rpcResp.Error.Code, rpcResp.Error.Message)
+    }
+    if err := json.Unmarshal(rpcResp.Result, result); err != nil {
+        return fmt.Errorf("failed to decode relay %s result: %w", method, err)
+    }
+    return nil
+}
+
+// Flashbots 签名头: 地址:personal_sign(keccak256(body) 的十六进制字符串)
+func (r *relayClient) sign(body []byte) (string, error) {
+    digest := hexutil.Encode(crypto.Keccak256(body))
+    signature, err := crypto.Sign(accounts.TextHash([]byte(digest)), r.authKey)
+    if err != nil {
+        return "", fmt.Errorf("failed to sign relay request: %w", err)
+    }
+    address := crypto.PubkeyToAddress(r.authKey.PublicKey)
+    return address.Hex() + ":" + hexutil.Encode(signature), nil
+}
+
+// 配置了中继时先提交到中继，中继拒绝时直接公开广播
+func (ec *EthereumClient) broadcast(ctx context.Context, tx *types.Transaction) (string, error) {
+    if ec.relay == nil {
+        if err := ec.chain.SendTransaction(ctx, tx); err != nil {
+            return "", err
+        }
+        return SubmissionPublic, nil
+    }
+
+    currentBlock, err := ec.chain.BlockNumber(ctx)
+    if err != nil {
+        return "", fmt.Errorf("failed to get block number: %w", err)
+    }
+    lastBlock := currentBlock + ec.relayFallbackBlocks()
+
+    if err := ec.relay.submit(ctx, tx, currentBlock+1, lastBlock); err != nil {
+        ec.logger.Warn("Relay submission failed, broadcasting publicly",
+            zap.String("hash", tx.Hash().Hex()),
+            zap.Error(err),
+        )
+        if err := ec.chain.SendTransaction(ctx, tx); err != nil {
+            return "", err
+        }
+        return SubmissionRelayFallback, nil
+    }
+
+    // 中继的交易不在节点的 pending 中，回退窗口结束前不能让 nonce 管理器把它当作已丢弃
+    ec.walletMgr.HoldNonce(tx.Nonce())
+    ec.watchers.Add(1)
+    go func() {
+        defer ec.watchers.Done()
+        ec.watchRelayedTransaction(ec.ctx, tx, currentBlock+1, lastBlock)
+    }()
+    return SubmissionRelay, nil
+}
+
+func (ec *EthereumClient) relayFallbackBlocks() uint64 {
+    if ec.config.RelayFallbackBlocks > 0 {
+        return ec.config.RelayFallbackBlocks
+    }
+    return defaultRelayFallbackBlocks
+}
+
+// bundle 模式下每出一个新区块提交下一个区块的 bundle，超过 lastBlock 仍未上链时改为公开广播；
+// 该 nonce 已上链或交易已被替换时停止，被替换的交易不再提交后续区块的 bundle。
+// ctx 取消 (客户端关闭)、连续查询失败过多或运行超时时也会停止，退出时释放持有的 nonce
+func (ec *EthereumClient) watchRelayedTransaction(ctx context.Context, tx *types.Transaction, bundleBlock, lastBlock uint64) {
+    from := ec.walletMgr.GetAddress()
+    defer ec.walletMgr.UnholdNonce(tx.Nonce())
+
+    timeout := time.Duration(lastBlock-bundleBlock+2) * relayWatchBlockTime * 2
+    ctx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+
+    interval := ec.pollInterval
+    if interval <= 0 {
+        interval = txStatusPollInterval
+    }
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    failures := 0
+    for {
+        select {
+        case <-ctx.Done():
+            ec.logger.Warn("Stopped watching relayed transaction",
+                zap.String("hash", tx.Hash().Hex()),
+                zap.Error(ctx.Err()),
+            )
+            return
+        case <-ticker.C:
+        }
+
+        if !ec.txs.isLatest(tx.Hash()) {
+            return
+        }
+
+        mined, err := ec.chain.NonceAt(ctx, from, nil)
+        if err == nil && mined > tx.Nonce() {
+            return
+        }
+        var block uint64
+        if err == nil {
+            block, err = ec.chain.BlockNumber(ctx)
+        }
+        if err != nil {
+            failures++
+            if failures >= relayWatchMaxFailures {
+                ec.logger.Error("Stopped watching relayed transaction after repeated failures",
+                    zap.String("hash", tx.Hash().Hex()),
+                    zap.Error(err),
+                )
+                return
+            }
+            continue
+        }
+        failures = 0
+
+        if block <= lastBlock {
+            if ec.relay.mode == RelayModeBundle && block+1 > bundleBlock && block+1 <= lastBlock {
+                bundleBlock = block + 1
+                if err := ec.relay.submit(ctx, tx, bundleBlock, lastBlock); err != nil {
+                    ec.logger.Warn("Relay bundle submission failed",
+                        zap.String("hash", tx.Hash().Hex()),
+                        zap.Uint64("block", bundleBlock),
+                        zap.Error(err),
+                    )
+                }
+            }
+            continue
+        }
+
+        ec.logger.Warn("Relayed transaction not included, broadcasting publicly",
+            zap.String("hash", tx.Hash().Hex()),
+            zap.Uint64("last_block", lastBlock),
+        )
+        if err := ec.chain.SendTransaction(ctx, tx); err != nil {
+            ec.logger.Error("Public fallback broadcast failed",
+                zap.String("hash", tx.Hash().Hex()),
+                zap.Error(err),
+            )
+        } else {
+            ec.txs.setSubmission(tx.Hash(), SubmissionRelayFallback)
+        }
+        return
+    }
+}
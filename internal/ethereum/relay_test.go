@@ -0,0 +1,296 @@
+package ethereum
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "io"
+    "math/big"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/ethereum/go-ethereum/accounts"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/common/hexutil"
+    "github.com/ethereum/go-ethereum/core/types"
+    "github.com/ethereum/go-ethereum/crypto"
+    "github.com/stretchr/testify/assert"
+    "go.uber.org/zap"
+
+    "github.com/your-username/ethereum-trading-mcp/internal/wallet"
+)
+
+// 本地模拟的中继，记录收到的请求并校验签名头
+type fakeRelay struct {
+    t       *testing.T
+    mu      sync.Mutex
+    calls   []relayRequest
+    signers []common.Address
+    fail    string
+}
+
+func (f *fakeRelay) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    body, err := io.ReadAll(r.Body)
+    assert.NoError(f.t, err)
+
+    var req relayRequest
+    assert.NoError(f.t, json.Unmarshal(body, &req))
+
+    header := r.Header.Get(relaySignatureHeader)
+    parts := strings.SplitN(header, ":", 2)
+    assert.Len(f.t, parts, 2)
+    sig, err := hexutil.Decode(parts[1])
+    assert.NoError(f.t, err)
+    digest := hexutil.Encode(crypto.Keccak256(body))
+    pub, err := crypto.SigToPub(accounts.TextHash([]byte(digest)), sig)
+    assert.NoError(f.t, err)
+    signer := crypto.PubkeyToAddress(*pub)
+    assert.Equal(f.t, common.HexToAddress(parts[0]), signer)
+
+    f.mu.Lock()
+    f.calls = append(f.calls, req)
+    f.signers = append(f.signers, signer)
+    f.mu.Unlock()
+
+    w.Header().Set("Content-Type", "application/json")
+    if f.fail != "" {
+        json.NewEncoder(w).Encode(map[string]interface{}{
+            "jsonrpc": "2.0",
+            "id":      req.ID,
+            "error":   map[string]interface{}{"code": -32000, "message": f.fail},
+        })
+        return
+    }
+
+    var result interface{}
+    switch req.Method {
+    case "eth_sendBundle":
+        result = map[string]string{"bundleHash": common.HexToHash("0xb0").Hex()}
+    case "eth_sendPrivateTransaction":
+        params := req.Params[0].(map[string]interface{})
+        raw, _ := hexutil.Decode(params["tx"].(string))
+        var tx types.Transaction
+        assert.NoError(f.t, tx.UnmarshalBinary(raw))
+        result = tx.Hash().Hex()
+    }
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "jsonrpc": "2.0",
+        "id":      req.ID,
+        "result":  result,
+    })
+}
+
+func newRelayTestTx(t *testing.T) *types.Transaction {
+    key, err := crypto.GenerateKey()
+    assert.NoError(t, err)
+    to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+    tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+        ChainID:   big.NewInt(1),
+        Nonce:     3,
+        GasTipCap: big.NewInt(1000000000),
+        GasFeeCap: big.NewInt(30000000000),
+        Gas:       21000,
+        To:        &to,
+        Value:     big.NewInt(0),
+    })
+    assert.NoError(t, err)
+    return tx
+}
+
+func TestRelayClient_SendPrivateTransaction(t *testing.T) {
+    relay := &fakeRelay{t: t}
+    server := httptest.NewServer(relay)
+    defer server.Close()
+
+    authKey, err := crypto.GenerateKey()
+    assert.NoError(t, err)
+    client, err := newRelayClient(server.URL, RelayModePrivate, authKey)
+    assert.NoError(t, err)
+
+    tx := newRelayTestTx(t)
+    assert.NoError(t, client.submit(context.Background(), tx, 101, 125))
+
+    assert.Len(t, relay.calls, 1)
+    call := relay.calls[0]
+    assert.Equal(t, "eth_sendPrivateTransaction", call.Method)
+    params := call.Params[0].(map[string]interface{})
+    assert.Equal(t, hexutil.EncodeUint64(125), params["maxBlockNumber"])
+    assert.Equal(t, crypto.PubkeyToAddress(authKey.PublicKey), relay.signers[0])
+}
+
+func TestRelayClient_SendBundle(t *testing.T) {
+    relay := &fakeRelay{t: t}
+    server := httptest.NewServer(relay)
+    defer server.Close()
+
+    client, err := newRelayClient(server.URL, RelayModeBundle, nil)
+    assert.NoError(t, err)
+
+    tx := newRelayTestTx(t)
+    raw, err := tx.MarshalBinary()
+    assert.NoError(t, err)
+    assert.NoError(t, client.submit(context.Background(), tx, 101, 125))
+
+    // 只提交目标区块的 bundle，后续区块由 watcher 逐块提交
+    assert.Len(t, relay.calls, 1)
+    call := relay.calls[0]
+    assert.Equal(t, "eth_sendBundle", call.Method)
+    params := call.Params[0].(map[string]interface{})
+    assert.Equal(t, hexutil.EncodeUint64(101), params["blockNumber"])
+    assert.Equal(t, []interface{}{hexutil.Encode(raw)}, params["txs"])
+}
+
+func TestRelayClient_Error(t *testing.T) {
+    relay := &fakeRelay{t: t, fail: "bundle simulation reverted"}
+    server := httptest.NewServer(relay)
+    defer server.Close()
+
+    client, err := newRelayClient(server.URL, "", nil)
+    assert.NoError(t, err)
+
+    err = client.submit(context.Background(), newRelayTestTx(t), 101, 125)
+    assert.Error(t, err)
+    assert.Contains(t, err.Error(), "bundle simulation reverted")
+}
+
+func TestNewRelayClient_InvalidMode(t *testing.T) {
+    _, err := newRelayClient("http://localhost", "mempool", nil)
+    assert.Error(t, err)
+}
+
+// 模拟链：每次查询区块高度前进一个区块，记录公开广播的交易
+type fakeRelayChain struct {
+    mu    sync.Mutex
+    block uint64
+    nonce uint64
+    fail  error
+    sent  []common.Hash
+}
+
+func (f *fakeRelayChain) BlockNumber(ctx context.Context) (uint64, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if f.fail != nil {
+        return 0, f.fail
+    }
+    f.block++
+    return f.block, nil
+}
+
+func (f *fakeRelayChain) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if f.fail != nil {
+        return 0, f.fail
+    }
+    return f.nonce, nil
+}
+
+func (f *fakeRelayChain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.sent = append(f.sent, tx.Hash())
+    return nil
+}
+
+func newRelayWatchTestClient(t *testing.T, chain *fakeRelayChain, relayURL string) *EthereumClient {
+    key, err := crypto.GenerateKey()
+    assert.NoError(t, err)
+    walletMgr, err := wallet.NewWalletManager(&wallet.WalletConfig{
+        PrivateKey:  hexutil.Encode(crypto.FromECDSA(key))[2:],
+        RPCEndpoint: "http://127.0.0.1:1",
+        ChainID:     1,
+    }, zap.NewNop())
+    assert.NoError(t, err)
+
+    relay, err := newRelayClient(relayURL, RelayModePrivate, nil)
+    assert.NoError(t, err)
+
+    ctx, cancel := context.WithCancel(context.Background())
+    return &EthereumClient{
+        chain:        chain,
+        ctx:          ctx,
+        cancel:       cancel,
+        pollInterval: time.Millisecond,
+        walletMgr:    walletMgr,
+        logger:       zap.NewNop(),
+        config:       &EthereumConfig{RelayFallbackBlocks: 3},
+        relay:        relay,
+        txs:          newTxTracker(),
+    }
+}
+
+func TestWatchRelayedTransaction_PublicFallback(t *testing.T) {
+    server := httptest.NewServer(&fakeRelay{t: t})
+    defer server.Close()
+
+    // nonce 一直停在交易的 nonce，超过回退窗口后公开广播
+    chain := &fakeRelayChain{block: 100, nonce: 3}
+    ec := newRelayWatchTestClient(t, chain, server.URL)
+    tx := newRelayTestTx(t)
+    ec.txs.track(tx, "", SubmissionRelay)
+
+    submission, err := ec.broadcast(context.Background(), tx)
+    assert.NoError(t, err)
+    assert.Equal(t, SubmissionRelay, submission)
+
+    ec.watchers.Wait()
+    assert.Equal(t, []common.Hash{tx.Hash()}, chain.sent)
+    assert.Greater(t, chain.block, uint64(104))
+    assert.Equal(t, SubmissionRelayFallback, ec.txs.submission(tx.Hash()))
+}
+
+func TestWatchRelayedTransaction_Mined(t *testing.T) {
+    server := httptest.NewServer(&fakeRelay{t: t})
+    defer server.Close()
+
+    chain := &fakeRelayChain{block: 100, nonce: 4}
+    ec := newRelayWatchTestClient(t, chain, server.URL)
+    tx := newRelayTestTx(t)
+    ec.txs.track(tx, "", SubmissionRelay)
+
+    _, err := ec.broadcast(context.Background(), tx)
+    assert.NoError(t, err)
+
+    ec.watchers.Wait()
+    assert.Empty(t, chain.sent)
+    assert.Equal(t, SubmissionRelay, ec.txs.submission(tx.Hash()))
+}
+
+func TestWatchRelayedTransaction_StopsAfterFailures(t *testing.T) {
+    ec := newRelayWatchTestClient(t, &fakeRelayChain{fail: errors.New("connection refused")}, "http://127.0.0.1:1")
+    tx := newRelayTestTx(t)
+
+    ec.watchRelayedTransaction(ec.ctx, tx, 101, 103)
+    assert.Empty(t, ec.chain.(*fakeRelayChain).sent)
+}
+
+func TestClose_StopsRelayWatcher(t *testing.T) {
+    // 区块高度不再前进时 watcher 一直等待，Close 取消后退出
+    chain := &fakeRelayChain{nonce: 3}
+    ec := newRelayWatchTestClient(t, chain, "http://127.0.0.1:1")
+    ec.pollInterval = time.Hour
+    tx := newRelayTestTx(t)
+
+    ec.watchers.Add(1)
+    go func() {
+        defer ec.watchers.Done()
+        ec.watchRelayedTransaction(ec.ctx, tx, 101, 125)
+    }()
+
+    done := make(chan struct{})
+    go func() {
+        ec.Close()
+        close(done)
+    }()
+    select {
+    case <-done:
+    case <-time.After(5 * time.Second):
+        t.Fatal("Close did not stop the relay watcher")
+    }
+    assert.Empty(t, chain.sent)
+}
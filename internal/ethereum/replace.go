@@ -0,0 +1,236 @@
+//卡住交易的加速和取消
+package ethereum
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "math/big"
+
+    geth "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/common/hexutil"
+    "github.com/ethereum/go-ethereum/core/types"
+    "go.uber.org/zap"
+
+    "github.com/your-username/ethereum-trading-mcp/internal/wallet"
+    "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
+)
+
+// geth 交易池要求替换交易的 tip 和 maxFee (legacy 为 gas price) 都至少提高 10%
+const (
+    minReplacementBumpPercent     = 10
+    defaultReplacementBumpPercent = 15
+    maxReplacementBumpPercent     = 500
+)
+
+// 取消交易是发给自己的 0 ETH 转账
+const cancelTxGasLimit = 21000
+
+type ReplaceTransactionRequest struct {
+    // 原始交易或任意一笔替换交易的哈希
+    TxHash string `json:"tx_hash"`
+    // 手续费相对上一笔提高的百分比，不能低于节点的替换下限
+    FeeBumpPercent int64 `json:"fee_bump_percent"`
+}
+
+type ReplaceTransactionResponse struct {
+    Kind           string           `json:"kind"`
+    OriginalTxHash string           `json:"original_tx_hash,omitempty"`
+    ReplacedTxHash string           `json:"replaced_tx_hash,omitempty"`
+    TxHash         string           `json:"tx_hash,omitempty"`
+    QuoteID        string           `json:"quote_id,omitempty"`
+    Nonce          uint64           `json:"nonce"`
+    FeeModel       string           `json:"fee_model,omitempty"`
+    GasPrice       *decimal.Decimal `json:"gas_price,omitempty"`
+    PriorityFee    *decimal.Decimal `json:"priority_fee,omitempty"`
+    MaxFeePerGas   *decimal.Decimal `json:"max_fee_per_gas,omitempty"`
+    Submission     string           `json:"submission,omitempty"`
+    Replacements   []TxReplacement  `json:"replacements,omitempty"`
+    Success        bool             `json:"success"`
+    Error          *string          `json:"error,omitempty"`
+}
+
+// 用更高的手续费重发同一 nonce 的相同交易
+func (ec *EthereumClient) SpeedUpTransaction(ctx context.Context, req *ReplaceTransactionRequest) (*ReplaceTransactionResponse, error) {
+    return ec.replaceTransaction(ctx, req, TxKindSpeedUp)
+}
+
+// 用更高的手续费在同一 nonce 上发送给自己的 0 ETH 转账，使原交易失效
+func (ec *EthereumClient) CancelTransaction(ctx context.Context, req *ReplaceTransactionRequest) (*ReplaceTransactionResponse, error) {
+    return ec.replaceTransaction(ctx, req, TxKindCancel)
+}
+
+func (ec *EthereumClient) replaceTransaction(ctx context.Context, req *ReplaceTransactionRequest, kind string) (*ReplaceTransactionResponse, error) {
+    resp := &ReplaceTransactionResponse{
+        Kind:    kind,
+        Success: false,
+    }
+
+    bump := int64(defaultReplacementBumpPercent)
+    if req.FeeBumpPercent != 0 {
+        bump = req.FeeBumpPercent
+    }
+    if bump < minReplacementBumpPercent || bump > maxReplacementBumpPercent {
+        resp.Error = stringPtr(fmt.Sprintf("fee_bump_percent must be between %d and %d", minReplacementBumpPercent, maxReplacementBumpPercent))
+        return resp, nil
+    }
+
+    hash, err := parseTxHash(req.TxHash)
+    if err != nil {
+        resp.Error = stringPtr(err.Error())
+        return resp, nil
+    }
+
+    entry, err := ec.trackedTransaction(ctx, hash)
+    if err != nil {
+        resp.Error = stringPtr(err.Error())
+        return resp, nil
+    }
+
+    entry.mu.Lock()
+    defer entry.mu.Unlock()
+
+    prev := entry.latest
+    resp.OriginalTxHash = entry.original.Hex()
+    resp.ReplacedTxHash = prev.Hash().Hex()
+    resp.QuoteID = entry.quoteID
+    resp.Nonce = prev.Nonce()
+
+    // 该 nonce 已经有交易上链时无法再替换
+    from := ec.walletMgr.GetAddress()
+    mined, err := ec.client.NonceAt(ctx, from, nil)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get nonce of %s: %w", from.Hex(), err)
+    }
+    if mined > prev.Nonce() {
+        resp.Error = stringPtr(fmt.Sprintf("nonce %d has already been mined, nothing to replace; check get_transaction_status for %s", prev.Nonce(), prev.Hash().Hex()))
+        return resp, nil
+    }
+
+    fees, err := ec.walletMgr.SuggestFees(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    to, data, value, gas := prev.To(), prev.Data(), prev.Value(), prev.Gas()
+    if kind == TxKindCancel {
+        to, data, value, gas = &from, nil, big.NewInt(0), cancelTxGasLimit
+    }
+
+    // 新手续费取上一笔提高 bump 之后和当前建议值中较大的
+    var tx *types.Transaction
+    if prev.Type() == types.LegacyTxType {
+        gasPrice := maxBig(bumpFee(prev.GasPrice(), bump), fees.EffectiveGasPrice())
+        tx = types.NewTx(&types.LegacyTx{
+            Nonce:    prev.Nonce(),
+            GasPrice: gasPrice,
+            Gas:      gas,
+            To:       to,
+            Value:    value,
+            Data:     data,
+        })
+        price := decimal.FromWei(gasPrice)
+        resp.FeeModel = wallet.FeeModelLegacy
+        resp.GasPrice = &price
+    } else {
+        tip := bumpFee(prev.GasTipCap(), bump)
+        feeCap := bumpFee(prev.GasFeeCap(), bump)
+        if !fees.Legacy {
+            tip = maxBig(tip, fees.GasTipCap)
+            feeCap = maxBig(feeCap, fees.GasFeeCap)
+        }
+        feeCap = maxBig(feeCap, tip)
+        tx = types.NewTx(&types.DynamicFeeTx{
+            ChainID:   ec.walletMgr.GetChainID(),
+            Nonce:     prev.Nonce(),
+            GasTipCap: tip,
+            GasFeeCap: feeCap,
+            Gas:       gas,
+            To:        to,
+            Value:     value,
+            Data:      data,
+        })
+        tipDec := decimal.FromWei(tip)
+        feeCapDec := decimal.FromWei(feeCap)
+        resp.FeeModel = wallet.FeeModelEIP1559
+        resp.PriorityFee = &tipDec
+        resp.MaxFeePerGas = &feeCapDec
+    }
+
+    // nonce 沿用原交易，不经过 nonce 管理器分配
+    signedTx, err := ec.walletMgr.SignTx(tx)
+    if err != nil {
+        return nil, err
+    }
+    submission, err := ec.broadcast(ctx, signedTx)
+    if err != nil {
+        resp.Error = stringPtr(fmt.Sprintf("failed to send replacement transaction: %v", err))
+        return resp, nil
+    }
+    ec.txs.replace(entry, signedTx, kind, submission)
+
+    ec.logger.Info("Transaction replaced",
+        zap.String("kind", kind),
+        zap.String("original", entry.original.Hex()),
+        zap.String("replaced", prev.Hash().Hex()),
+        zap.String("hash", signedTx.Hash().Hex()),
+        zap.Uint64("nonce", signedTx.Nonce()),
+    )
+
+    resp.TxHash = signedTx.Hash().Hex()
+    resp.Submission = submission
+    resp.Replacements = ec.txs.replacements(entry)
+    resp.Success = true
+    return resp, nil
+}
+
+// 本地没有记录的交易 (例如服务重启前发出的) 从节点查询，只接受本钱包发出且仍在 pending 的交易
+func (ec *EthereumClient) trackedTransaction(ctx context.Context, hash common.Hash) (*trackedTx, error) {
+    if entry, ok := ec.txs.lookup(hash); ok {
+        return entry, nil
+    }
+
+    tx, isPending, err := ec.client.TransactionByHash(ctx, hash)
+    if errors.Is(err, geth.NotFound) {
+        return nil, fmt.Errorf("transaction %s not found", hash.Hex())
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get transaction %s: %w", hash.Hex(), err)
+    }
+    if !isPending {
+        return nil, fmt.Errorf("transaction %s is already mined", hash.Hex())
+    }
+
+    from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to recover sender of %s: %w", hash.Hex(), err)
+    }
+    if from != ec.walletMgr.GetAddress() {
+        return nil, fmt.Errorf("transaction %s was sent by %s, not by this wallet", hash.Hex(), from.Hex())
+    }
+
+    return ec.txs.track(tx, "", ""), nil
+}
+
+func parseTxHash(s string) (common.Hash, error) {
+    hashBytes, err := hexutil.Decode(s)
+    if err != nil || len(hashBytes) != common.HashLength {
+        return common.Hash{}, fmt.Errorf("invalid transaction hash: %s", s)
+    }
+    return common.BytesToHash(hashBytes), nil
+}
+
+// 按百分比提高手续费，向上取整保证达到节点的替换下限
+func bumpFee(fee *big.Int, percent int64) *big.Int {
+    bumped := new(big.Int).Mul(fee, big.NewInt(100+percent))
+    bumped.Add(bumped, big.NewInt(99))
+    return bumped.Div(bumped, big.NewInt(100))
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+    if b != nil && b.Cmp(a) > 0 {
+        return new(big.Int).Set(b)
+    }
+    return a
+}
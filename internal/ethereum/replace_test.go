@@ -0,0 +1,69 @@
+package ethereum
+
+import (
+    "math/big"
+    "testing"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/core/types"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestBumpFee(t *testing.T) {
+    assert.Equal(t, big.NewInt(110), bumpFee(big.NewInt(100), 10))
+    assert.Equal(t, big.NewInt(11), bumpFee(big.NewInt(10), 10))
+    // 向上取整，不能因为截断低于节点的替换下限: 11 * 1.1 = 12.1
+    assert.Equal(t, big.NewInt(13), bumpFee(big.NewInt(11), 10))
+    assert.Equal(t, big.NewInt(1150000000), bumpFee(big.NewInt(1000000000), 15))
+}
+
+func TestTxTracker_ReplacementChain(t *testing.T) {
+    tracker := newTxTracker()
+    to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+    newTx := func(tip int64) *types.Transaction {
+        return types.NewTx(&types.DynamicFeeTx{
+            ChainID:   big.NewInt(1),
+            Nonce:     7,
+            GasTipCap: big.NewInt(tip),
+            GasFeeCap: big.NewInt(tip * 2),
+            Gas:       21000,
+            To:        &to,
+            Value:     big.NewInt(0),
+        })
+    }
+
+    original := newTx(100)
+    speedUp := newTx(115)
+    cancel := newTx(133)
+
+    tracker.track(original, "quote-1", SubmissionPublic)
+    entry, ok := tracker.lookup(original.Hash())
+    assert.True(t, ok)
+    tracker.replace(entry, speedUp, TxKindSpeedUp, SubmissionPublic)
+    tracker.replace(entry, cancel, TxKindCancel, SubmissionRelay)
+
+    // 替换链中任意一笔交易都能找到同一条记录
+    for _, tx := range []*types.Transaction{original, speedUp, cancel} {
+        found, ok := tracker.lookup(tx.Hash())
+        assert.True(t, ok)
+        assert.Same(t, entry, found)
+    }
+
+    assert.Equal(t, original.Hash(), entry.original)
+    assert.Equal(t, cancel.Hash(), entry.latest.Hash())
+    assert.Equal(t, "quote-1", entry.quoteID)
+    assert.Len(t, entry.chain, 3)
+    assert.Equal(t, TxKindOriginal, entry.chain[0].Kind)
+    assert.Equal(t, TxKindSpeedUp, entry.chain[1].Kind)
+    assert.Equal(t, TxKindCancel, entry.chain[2].Kind)
+
+    assert.False(t, tracker.isLatest(speedUp.Hash()))
+    assert.True(t, tracker.isLatest(cancel.Hash()))
+    assert.Equal(t, SubmissionRelay, tracker.submission(cancel.Hash()))
+
+    latest, ok := tracker.latestHash(original.Hash())
+    assert.True(t, ok)
+    assert.Equal(t, cancel.Hash(), latest)
+    _, ok = tracker.latestHash(common.HexToHash("0x01"))
+    assert.False(t, ok)
+}
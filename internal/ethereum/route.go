@@ -0,0 +1,224 @@
+//swap 路由选择
+package ethereum
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "math/big"
+    "sort"
+    "sync"
+
+    "github.com/ethereum/go-ethereum/common"
+    "go.uber.org/zap"
+
+    "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
+)
+
+const (
+    RoutingAuto = "auto"
+    RoutingV2   = "v2"
+    RoutingV3   = "v3"
+)
+
+// 未被选中的候选路由，方便 agent 解释选择理由
+type RouteAlternative struct {
+    Router          string           `json:"router"`
+    FeeTier         *uint32          `json:"fee_tier,omitempty"`
+    Hops            []SwapHop        `json:"hops,omitempty"`
+    EstimatedOutput *decimal.Decimal `json:"estimated_output,omitempty"`
+    GasCostUSD      *decimal.Decimal `json:"gas_cost_usd,omitempty"`
+    NetOutput       *decimal.Decimal `json:"net_output,omitempty"`
+    InputAmount     *decimal.Decimal `json:"input_amount,omitempty"`
+    NetInput        *decimal.Decimal `json:"net_input,omitempty"`
+    Reason          string           `json:"reason"`
+}
+
+type routeCandidate struct {
+    router   string
+    feeTier  *uint32
+    multiHop bool
+    resp     *SwapResponse
+    err      error
+    // 精确输入时为扣除 gas 后的输出，精确输出时为加上 gas 后的输入
+    net decimal.Decimal
+}
+
+// 并行报价 V2 和所有 V3 手续费档位，按扣除 gas 后的净输出 (精确输出时按加上 gas 后的净输入) 选择最优路由
+func (ec *EthereumClient) findBestRoute(ctx context.Context, req *SwapRequest, pair *swapPair) (*SwapResponse, error) {
+    candidates := make([]*routeCandidate, 0, len(v3FeeTiers)+1)
+    candidates = append(candidates, &routeCandidate{router: "Uniswap V2"})
+    for _, fee := range v3FeeTiers {
+        fee := fee
+        candidates = append(candidates, &routeCandidate{router: "Uniswap V3", feeTier: &fee})
+    }
+    if !pair.ExactOutput && len(ec.candidatePaths(pair.From, pair.To)) > 1 {
+        candidates = append(candidates, &routeCandidate{router: "Uniswap V3", multiHop: true})
+    }
+
+    var wg sync.WaitGroup
+    for _, c := range candidates {
+        wg.Add(1)
+        go func(c *routeCandidate) {
+            defer wg.Done()
+            switch {
+            case c.multiHop:
+                c.resp, c.err = ec.simulateUniswapV3MultiHopSwap(ctx, req, pair)
+            case c.feeTier != nil:
+                c.resp, c.err = ec.simulateUniswapV3SwapWithFee(ctx, req, pair, *c.feeTier)
+            default:
+                c.resp, c.err = ec.simulateUniswapV2Swap(ctx, req, pair)
+            }
+        }(c)
+    }
+    wg.Wait()
+
+    succeeded, err := ec.usableRoutes(candidates)
+    if err != nil {
+        return nil, err
+    }
+
+    // gas 成本换算成输出代币 (精确输出时换算成输入代币)，换算失败时退化为按毛数量比较
+    rate, err := ec.tokenPerETH(ctx, pair, succeeded[0].resp)
+    if err != nil {
+        ec.logger.Warn("Failed to price gas in swap token, ranking routes by gross amount",
+            zap.String("from_token", pair.From.Hex()),
+            zap.String("to_token", pair.To.Hex()),
+            zap.Error(err),
+        )
+    }
+    rankRoutes(succeeded, pair.ExactOutput, rate)
+
+    best := succeeded[0]
+    result := best.resp
+    result.Routing = RoutingAuto
+    if rate != nil {
+        if pair.ExactOutput {
+            result.NetInput = &best.net
+        } else {
+            result.NetOutput = &best.net
+        }
+    }
+
+    for _, c := range candidates {
+        if c == best {
+            continue
+        }
+        alt := RouteAlternative{
+            Router:  c.router,
+            FeeTier: c.feeTier,
+        }
+        if c.err != nil {
+            alt.Reason = c.err.Error()
+        } else {
+            alt.Hops = c.resp.Hops
+            alt.EstimatedOutput = &c.resp.EstimatedOutput
+            alt.GasCostUSD = &c.resp.GasCostUSD
+            net := c.net
+            switch {
+            case pair.ExactOutput && rate != nil:
+                alt.InputAmount = &c.resp.InputAmount
+                alt.NetInput = &net
+                alt.Reason = "higher input net of gas than selected route"
+            case pair.ExactOutput:
+                alt.InputAmount = &c.resp.InputAmount
+                alt.Reason = "higher input than selected route"
+            case rate != nil:
+                alt.NetOutput = &net
+                alt.Reason = "lower output net of gas than selected route"
+            default:
+                alt.Reason = "lower output than selected route"
+            }
+        }
+        result.Alternatives = append(result.Alternatives, alt)
+    }
+
+    return result, nil
+}
+
+// 报价成功且价格影响没有超过拒绝阈值的候选路由，被拒绝的路由记录原因后由次优的路由替代
+func (ec *EthereumClient) usableRoutes(candidates []*routeCandidate) ([]*routeCandidate, error) {
+    var usable []*routeCandidate
+    var lastErr error
+    for _, c := range candidates {
+        if c.err != nil {
+            lastErr = c.err
+            continue
+        }
+        ec.checkPriceImpact(c.resp)
+        if !c.resp.Success {
+            c.err = errors.New(*c.resp.Error)
+            lastErr = c.err
+            continue
+        }
+        usable = append(usable, c)
+    }
+    if len(usable) == 0 {
+        return nil, lastErr
+    }
+    return usable, nil
+}
+
+// 计算扣除 gas 后的净数量并从优到劣排序，rate 为每个 ETH 可换得的代币数量，为空时按毛数量比较
+func rankRoutes(candidates []*routeCandidate, exactOutput bool, rate *decimal.Decimal) {
+    for _, c := range candidates {
+        if exactOutput {
+            c.net = c.resp.InputAmount
+            if rate != nil {
+                c.net = c.resp.InputAmount.Add(gasCostETH(c.resp).Mul(*rate))
+            }
+        } else {
+            c.net = c.resp.EstimatedOutput
+            if rate != nil {
+                c.net = c.resp.EstimatedOutput.Sub(gasCostETH(c.resp).Mul(*rate))
+            }
+        }
+    }
+    sort.SliceStable(candidates, func(i, j int) bool {
+        if exactOutput {
+            return candidates[i].net.LessThan(candidates[j].net)
+        }
+        return candidates[i].net.GreaterThan(candidates[j].net)
+    })
+}
+
+// 每个 ETH 可换得的输出代币 (精确输出时为输入代币) 数量，用候选路由的 gas 成本作为报价数量
+func (ec *EthereumClient) tokenPerETH(ctx context.Context, pair *swapPair, sample *SwapResponse) (*decimal.Decimal, error) {
+    token, decimals := pair.To, pair.ToDecimals
+    if pair.ExactOutput {
+        token, decimals = pair.From, pair.FromDecimals
+    }
+    if token == common.HexToAddress(ec.config.WETHAddress) {
+        one := decimal.NewFromInt(1)
+        return &one, nil
+    }
+
+    gasWei := decimal.ToWei(gasCostETH(sample))
+    if gasWei.Sign() == 0 {
+        gasWei = big.NewInt(1e15)
+    }
+
+    amountOut, err := ec.convertFromETH(ctx, token, gasWei)
+    if err != nil {
+        return nil, err
+    }
+    rate := decimal.FormatBalance(amountOut, decimals).Div(decimal.FromWei(gasWei))
+    return &rate, nil
+}
+
+func (ec *EthereumClient) convertFromETH(ctx context.Context, token common.Address, amountWei *big.Int) (*big.Int, error) {
+    weth := common.HexToAddress(ec.config.WETHAddress)
+    v2, err := ec.findBestV2Route(ctx, weth, token, amountWei)
+    if err == nil {
+        return v2.AmountOut(), nil
+    }
+    v3, v3Err := ec.findBestV3Route(ctx, weth, token, amountWei, false)
+    if v3Err != nil {
+        return nil, fmt.Errorf("v2: %v; v3: %w", err, v3Err)
+    }
+    return v3.AmountOut(), nil
+}
+
+func gasCostETH(resp *SwapResponse) decimal.Decimal {
+    return resp.GasPrice.Mul(decimal.NewFromInt(int64(resp.GasEstimate)))
+}
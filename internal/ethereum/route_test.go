@@ -0,0 +1,79 @@
+package ethereum
+
+import (
+    "errors"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+
+    "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
+)
+
+func routeCandidateWith(router string, input, output, gasPriceGwei int64, gasEstimate uint64) *routeCandidate {
+    return &routeCandidate{
+        router: router,
+        resp: &SwapResponse{
+            InputAmount:     decimal.NewFromInt(input),
+            EstimatedOutput: decimal.NewFromInt(output),
+            GasPrice:        decimal.NewFromInt(gasPriceGwei).Shift(-9),
+            GasEstimate:     gasEstimate,
+            Success:         true,
+        },
+    }
+}
+
+func TestRankRoutes_NetOfGas(t *testing.T) {
+    // 多跳路由毛输出更高，但 gas 成本 (按 2000 代币/ETH 换算) 抵消了优势
+    direct := routeCandidateWith("direct", 1, 2000, 50, 100000)  // gas 0.005 ETH = 10
+    multiHop := routeCandidateWith("multi", 1, 2005, 50, 300000) // gas 0.015 ETH = 30
+    rate := decimal.NewFromInt(2000)
+
+    candidates := []*routeCandidate{multiHop, direct}
+    rankRoutes(candidates, false, &rate)
+    assert.Equal(t, "direct", candidates[0].router)
+    assert.True(t, decimal.NewFromInt(1990).Equal(candidates[0].net))
+    assert.True(t, decimal.NewFromInt(1975).Equal(candidates[1].net))
+
+    // 没有换算汇率时按毛输出比较
+    candidates = []*routeCandidate{direct, multiHop}
+    rankRoutes(candidates, false, nil)
+    assert.Equal(t, "multi", candidates[0].router)
+}
+
+func TestRankRoutes_ExactOutput(t *testing.T) {
+    // 精确输出时按加上 gas 后的净输入从小到大排序
+    cheap := routeCandidateWith("cheap_gas", 1010, 1000, 50, 100000)
+    cheapest := routeCandidateWith("cheap_input", 1000, 1000, 50, 300000)
+    rate := decimal.NewFromInt(2000)
+
+    candidates := []*routeCandidate{cheapest, cheap}
+    rankRoutes(candidates, true, &rate)
+    assert.Equal(t, "cheap_gas", candidates[0].router)
+    assert.True(t, decimal.NewFromInt(1020).Equal(candidates[0].net))
+    assert.True(t, decimal.NewFromInt(1030).Equal(candidates[1].net))
+}
+
+func TestUsableRoutes_SkipsBlockedImpact(t *testing.T) {
+    ec := &EthereumClient{config: &EthereumConfig{PriceImpactBlockPercent: 5}}
+    withImpact := func(router string, output, percent int64) *routeCandidate {
+        c := routeCandidateWith(router, 1, output, 50, 100000)
+        impact := decimal.NewFromInt(percent)
+        c.resp.PriceImpact = &impact
+        return c
+    }
+
+    // 输出最高的路由价格影响超过阈值，由次优的路由替代
+    blocked := withImpact("blocked", 2100, 8)
+    ok := withImpact("ok", 1990, 1)
+    failed := &routeCandidate{router: "failed", err: errors.New("no pool")}
+
+    usable, err := ec.usableRoutes([]*routeCandidate{blocked, ok, failed})
+    assert.NoError(t, err)
+    assert.Len(t, usable, 1)
+    assert.Equal(t, "ok", usable[0].router)
+    assert.Error(t, blocked.err)
+
+    // 所有路由都被拒绝时返回原因
+    _, err = ec.usableRoutes([]*routeCandidate{withImpact("blocked", 2100, 8)})
+    assert.Error(t, err)
+}
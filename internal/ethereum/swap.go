@@ -1,11 +1,15 @@
 package ethereum
+
 //代币swap
 
 import (
     "context"
     "fmt"
     "math/big"
+    "time"
 
+    "github.com/ethereum/go-ethereum/accounts/abi"
+    "github.com/ethereum/go-ethereum/accounts/abi/bind"
     "github.com/ethereum/go-ethereum/common"
     "github.com/ethereum/go-ethereum/core/types"
     "go.uber.org/zap"
@@ -13,30 +17,161 @@ import (
     "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
 )
 
+// 交易截止时间，超过后 router 会拒绝执行
+const (
+    defaultSwapDeadline = 20 * time.Minute
+    maxSwapDeadline     = 24 * time.Hour
+)
+
+// Uniswap V3 的手续费档位 (0.01%, 0.05%, 0.3%, 1%)
+var v3FeeTiers = []uint32{100, 500, 3000, 10000}
+
 type SwapRequest struct {
-    FromToken       string          `json:"from_token"`
-    ToToken         string          `json:"to_token"`
-    Amount          decimal.Decimal `json:"amount"`
-    SlippageTolerance decimal.Decimal `json:"slippage_tolerance"` 
-    UseV3           bool            `json:"use_v3"`
+    FromToken         string          `json:"from_token"`
+    ToToken           string          `json:"to_token"`
+    Amount            decimal.Decimal `json:"amount"`
+    SlippageTolerance decimal.Decimal `json:"slippage_tolerance"`
+    UseV3             bool            `json:"use_v3"`
+    Routing           string          `json:"routing"`
+    // exact_output 时 Amount 为要买到的输出数量
+    TradeType string `json:"trade_type"`
+    // 为 0 时使用默认的 20 分钟
+    DeadlineSeconds int64 `json:"deadline_seconds"`
+    // 接收输出代币的地址，为空时为钱包地址
+    Recipient string `json:"recipient"`
 }
 
 type SwapResponse struct {
-    FromToken       string          `json:"from_token"`
-    ToToken         string          `json:"to_token"`
-    InputAmount     decimal.Decimal `json:"input_amount"`
-    EstimatedOutput decimal.Decimal `json:"estimated_output"`
-    MinOutput       decimal.Decimal `json:"min_output"` 
-    GasEstimate     uint64          `json:"gas_estimate"`
-    GasPrice        decimal.Decimal `json:"gas_price"`
-    GasCostUSD      decimal.Decimal `json:"gas_cost_usd"`
-    Slippage        decimal.Decimal `json:"slippage"`
-    Router          string          `json:"router"`
-    Success         bool            `json:"success"`
-    Error           *string         `json:"error,omitempty"`
+    FromToken       string             `json:"from_token"`
+    ToToken         string             `json:"to_token"`
+    TradeType       string             `json:"trade_type"`
+    InputAmount     decimal.Decimal    `json:"input_amount"`
+    MaxInput        *decimal.Decimal   `json:"max_input,omitempty"`
+    EstimatedOutput decimal.Decimal    `json:"estimated_output"`
+    MinOutput       decimal.Decimal    `json:"min_output"`
+    GasEstimate     uint64             `json:"gas_estimate"`
+    GasPrice        decimal.Decimal    `json:"gas_price"`
+    FeeModel        string             `json:"fee_model"`
+    BaseFee         *decimal.Decimal   `json:"base_fee,omitempty"`
+    PriorityFee     *decimal.Decimal   `json:"priority_fee,omitempty"`
+    MaxFeePerGas    *decimal.Decimal   `json:"max_fee_per_gas,omitempty"`
+    GasCostUSD      decimal.Decimal    `json:"gas_cost_usd"`
+    GasCostSource   string             `json:"gas_cost_usd_source"`
+    GasToken        string             `json:"gas_token"`
+    GasTokenPrice   *decimal.Decimal   `json:"gas_token_price_usd,omitempty"`
+    Slippage        decimal.Decimal    `json:"slippage"`
+    Recipient       string             `json:"recipient"`
+    RecipientName   *string            `json:"recipient_name,omitempty"`
+    Deadline        *time.Time         `json:"deadline,omitempty"`
+    Router          string             `json:"router"`
+    FeeTier         *uint32            `json:"fee_tier,omitempty"`
+    SqrtPriceAfter  *string            `json:"sqrt_price_x96_after,omitempty"`
+    TicksCrossed    *uint32            `json:"ticks_crossed,omitempty"`
+    Hops            []SwapHop          `json:"hops,omitempty"`
+    MidPrice        *decimal.Decimal   `json:"mid_price,omitempty"`
+    ExecutionPrice  decimal.Decimal    `json:"execution_price"`
+    PriceImpact     *decimal.Decimal   `json:"price_impact_percent,omitempty"`
+    ImpactWarning   *string            `json:"price_impact_warning,omitempty"`
+    AddressWarnings []string           `json:"address_warnings,omitempty"`
+    Routing         string             `json:"routing,omitempty"`
+    NetOutput       *decimal.Decimal   `json:"net_output,omitempty"`
+    NetInput        *decimal.Decimal   `json:"net_input,omitempty"`
+    Alternatives    []RouteAlternative `json:"alternatives,omitempty"`
+    ApprovalNeeded  bool               `json:"approval_needed"`
+    ApprovalMethod  string             `json:"approval_method"`
+    Approval        *TokenApproval     `json:"approval,omitempty"`
+    GasFallback     bool               `json:"gas_estimate_fallback,omitempty"`
+    QuoteID         string             `json:"quote_id,omitempty"`
+    BlockNumber     uint64             `json:"block_number,omitempty"`
+    ExpiresAt       *time.Time         `json:"expires_at,omitempty"`
+    Success         bool               `json:"success"`
+    Error           *string            `json:"error,omitempty"`
+
+    // 模拟时构建好的 router 交易，执行时直接使用
+    tx *swapTransaction
+    // 写入交易的数量限制，执行时固定使用报价时的限制
+    limits *swapLimits
 }
 
+type swapTransaction struct {
+    To    common.Address
+    Data  []byte
+    Value *big.Int
+}
+
+// 模拟 swap，成功时保存报价并返回 quote_id 供后续执行
 func (ec *EthereumClient) SwapTokens(ctx context.Context, req *SwapRequest) (*SwapResponse, error) {
+    // 报价基于的区块
+    blockNumber, err := ec.client.BlockNumber(ctx)
+    if err != nil {
+        return &SwapResponse{
+            Success: false,
+            Error:   stringPtr(fmt.Sprintf("failed to get block number: %v", err)),
+        }, nil
+    }
+
+    recipientInput := req.Recipient
+    // ENS 名称在保存报价前换成地址，执行时不再重新解析
+    req, err = ec.resolveSwapNames(ctx, req)
+    if err != nil {
+        return &SwapResponse{
+            Success: false,
+            Error:   stringPtr(err.Error()),
+        }, nil
+    }
+
+    addressWarnings, err := ec.checkSwapChecksums(req)
+    if err != nil {
+        return &SwapResponse{
+            Success: false,
+            Error:   stringPtr(err.Error()),
+        }, nil
+    }
+
+    result, err := ec.simulateSwap(ctx, req, nil)
+    if err != nil || !result.Success {
+        return result, err
+    }
+    result.AddressWarnings = addressWarnings
+    result.RecipientName = ec.addressName(ctx, recipientInput, common.HexToAddress(result.Recipient))
+
+    quote := ec.quotes.save(req, result, blockNumber)
+    result.QuoteID = quote.ID
+    result.BlockNumber = quote.BlockNumber
+    result.ExpiresAt = &quote.ExpiresAt
+
+    return result, nil
+}
+
+// 返回代币和接收地址中的 ENS 名称替换为地址后的请求副本
+func (ec *EthereumClient) resolveSwapNames(ctx context.Context, req *SwapRequest) (*SwapRequest, error) {
+    resolved := *req
+    if isENSName(req.FromToken) {
+        addr, err := ec.resolveENSName(ctx, req.FromToken)
+        if err != nil {
+            return nil, fmt.Errorf("invalid from token: %w", err)
+        }
+        resolved.FromToken = addr.Hex()
+    }
+    if isENSName(req.ToToken) {
+        addr, err := ec.resolveENSName(ctx, req.ToToken)
+        if err != nil {
+            return nil, fmt.Errorf("invalid to token: %w", err)
+        }
+        resolved.ToToken = addr.Hex()
+    }
+    if isENSName(req.Recipient) {
+        addr, err := ec.resolveENSName(ctx, req.Recipient)
+        if err != nil {
+            return nil, fmt.Errorf("invalid recipient: %w", err)
+        }
+        resolved.Recipient = addr.Hex()
+    }
+    return &resolved, nil
+}
+
+// pinned 不为空时交易的最少输出或最多输入固定为该限制
+func (ec *EthereumClient) simulateSwap(ctx context.Context, req *SwapRequest, pinned *swapLimits) (*SwapResponse, error) {
     if err := ec.validateSwapRequest(req); err != nil {
         return &SwapResponse{
             Success: false,
@@ -44,21 +179,35 @@ func (ec *EthereumClient) SwapTokens(ctx context.Context, req *SwapRequest) (*Sw
         }, nil
     }
 
-    // 获取代币地址
-    fromTokenAddr, toTokenAddr, err := ec.getTokenAddresses(req.FromToken, req.ToToken)
+    // 获取代币地址和精度
+    pair, err := ec.resolveSwapPair(ctx, req)
     if err != nil {
         return &SwapResponse{
             Success: false,
             Error:   stringPtr(err.Error()),
         }, nil
     }
+    // 执行报价时沿用报价的截止时间，写入交易的和返回给调用方的一致
+    if pinned != nil {
+        pair.Pinned = pinned
+        pair.Deadline = pinned.Deadline
+    }
 
-    // 模拟交易的过程
+    // 模拟交易的过程，use_v3 作为显式指定优先于 routing
     var result *SwapResponse
-    if req.UseV3 {
-        result, err = ec.simulateUniswapV3Swap(ctx, req, fromTokenAddr, toTokenAddr)
-    } else {
-        result, err = ec.simulateUniswapV2Swap(ctx, req, fromTokenAddr, toTokenAddr)
+    switch {
+    case req.UseV3 || req.Routing == RoutingV3:
+        result, err = ec.simulateUniswapV3Swap(ctx, req, pair)
+        if err == nil {
+            result.Routing = RoutingV3
+        }
+    case req.Routing == RoutingV2:
+        result, err = ec.simulateUniswapV2Swap(ctx, req, pair)
+        if err == nil {
+            result.Routing = RoutingV2
+        }
+    default:
+        result, err = ec.findBestRoute(ctx, req, pair)
     }
 
     if err != nil {
@@ -68,6 +217,8 @@ func (ec *EthereumClient) SwapTokens(ctx context.Context, req *SwapRequest) (*Sw
         }, nil
     }
 
+    ec.checkPriceImpact(result)
+
     return result, nil
 }
 
@@ -81,151 +232,628 @@ func (ec *EthereumClient) validateSwapRequest(req *SwapRequest) error {
     if req.FromToken == "" || req.ToToken == "" {
         return fmt.Errorf("from_token and to_token are required")
     }
+    switch req.Routing {
+    case "", RoutingAuto, RoutingV2, RoutingV3:
+    default:
+        return fmt.Errorf("routing must be one of %q, %q or %q", RoutingAuto, RoutingV2, RoutingV3)
+    }
+    // 直接比较秒数，换算成 time.Duration 时过大的值会溢出
+    if req.DeadlineSeconds < 0 || req.DeadlineSeconds > int64(maxSwapDeadline/time.Second) {
+        return fmt.Errorf("deadline_seconds must be between 0 and %d (0 uses the default of %d)",
+            int64(maxSwapDeadline/time.Second), int64(defaultSwapDeadline/time.Second))
+    }
+    if req.Recipient != "" {
+        recipient, err := ec.ValidateAddress(req.Recipient)
+        if err != nil {
+            return fmt.Errorf("invalid recipient: %w", err)
+        }
+        if recipient == (common.Address{}) {
+            return fmt.Errorf("recipient must not be the zero address")
+        }
+    }
+    switch req.TradeType {
+    case "", TradeTypeExactInput, TradeTypeExactOutput:
+    default:
+        return fmt.Errorf("trade_type must be %q or %q", TradeTypeExactInput, TradeTypeExactOutput)
+    }
     return nil
 }
 
 func (ec *EthereumClient) getTokenAddresses(fromToken, toToken string) (common.Address, common.Address, error) {
-    var fromAddr, toAddr common.Address
+    fromAddr, err := ec.resolveTokenAddress(fromToken)
+    if err != nil {
+        return common.Address{}, common.Address{}, fmt.Errorf("unknown from token: %s", fromToken)
+    }
 
-    if fromToken == "ETH" {
-        fromAddr = common.HexToAddress(ec.config.WETHAddress)
-    } else if common.IsHexAddress(fromToken) {
-        fromAddr = common.HexToAddress(fromToken)
-    } else {
-        fromAddr = getTokenAddressBySymbol(fromToken)
-        if fromAddr == (common.Address{}) {
-            return common.Address{}, common.Address{}, fmt.Errorf("unknown from token: %s", fromToken)
-        }
+    toAddr, err := ec.resolveTokenAddress(toToken)
+    if err != nil {
+        return common.Address{}, common.Address{}, fmt.Errorf("unknown to token: %s", toToken)
     }
 
-    if toToken == "ETH" {
-        toAddr = common.HexToAddress(ec.config.WETHAddress)
-    } else if common.IsHexAddress(toToken) {
-        toAddr = common.HexToAddress(toToken)
+    return fromAddr, toAddr, nil
+}
+
+// 一次 swap 的代币信息、按精度换算后的数量 (精确输出时只有 AmountOut)、接收地址和截止时间
+type swapPair struct {
+    From         common.Address
+    To           common.Address
+    FromDecimals int
+    ToDecimals   int
+    ExactOutput  bool
+    AmountIn     *big.Int
+    AmountOut    *big.Int
+    Recipient    common.Address
+    Deadline     time.Time
+    Pinned       *swapLimits
+}
+
+func (p *swapPair) deadlineUnix() *big.Int {
+    return big.NewInt(p.Deadline.Unix())
+}
+
+func (ec *EthereumClient) resolveSwapPair(ctx context.Context, req *SwapRequest) (*swapPair, error) {
+    fromAddr, toAddr, err := ec.getTokenAddresses(req.FromToken, req.ToToken)
+    if err != nil {
+        return nil, err
+    }
+
+    decimals, err := ec.tokensDecimals(ctx, []common.Address{fromAddr, toAddr})
+    if err != nil {
+        return nil, err
+    }
+    fromDecimals, toDecimals := decimals[0], decimals[1]
+
+    pair := &swapPair{
+        From:         fromAddr,
+        To:           toAddr,
+        FromDecimals: fromDecimals,
+        ToDecimals:   toDecimals,
+        ExactOutput:  req.TradeType == TradeTypeExactOutput,
+        Recipient:    ec.walletMgr.GetAddress(),
+        Deadline:     swapDeadline(req.DeadlineSeconds),
+    }
+    if req.Recipient != "" {
+        pair.Recipient = common.HexToAddress(req.Recipient)
+    }
+    if pair.ExactOutput {
+        pair.AmountOut, err = decimal.ToUnits(req.Amount, toDecimals)
+        if err != nil {
+            return nil, fmt.Errorf("invalid amount for %s: %w", req.ToToken, err)
+        }
     } else {
-        toAddr = getTokenAddressBySymbol(toToken)
-        if toAddr == (common.Address{}) {
-            return common.Address{}, common.Address{}, fmt.Errorf("unknown to token: %s", toToken)
+        pair.AmountIn, err = decimal.ToUnits(req.Amount, fromDecimals)
+        if err != nil {
+            return nil, fmt.Errorf("invalid amount for %s: %w", req.FromToken, err)
         }
     }
 
-    return fromAddr, toAddr, nil
+    return pair, nil
 }
 
-func (ec *EthereumClient) simulateUniswapV2Swap(ctx context.Context, req *SwapRequest, fromToken, toToken common.Address) (*SwapResponse, error) {
+// ETH 映射到 WETH，其余支持地址或已知符号
+func (ec *EthereumClient) resolveTokenAddress(token string) (common.Address, error) {
+    if token == "ETH" {
+        return common.HexToAddress(ec.config.WETHAddress), nil
+    }
+    if common.IsHexAddress(token) {
+        return common.HexToAddress(token), nil
+    }
+    addr := getTokenAddressBySymbol(token)
+    if addr == (common.Address{}) {
+        return common.Address{}, fmt.Errorf("unknown token: %s", token)
+    }
+    return addr, nil
+}
+
+func (ec *EthereumClient) simulateUniswapV2Swap(ctx context.Context, req *SwapRequest, pair *swapPair) (*SwapResponse, error) {
     routerAddress := common.HexToAddress(ec.config.UniswapV2Router)
-    amountIn := decimal.ToWei(req.Amount)
 
-    // 交易数据
-    var data []byte
-    if fromToken == common.HexToAddress(ec.config.WETHAddress) {
-        // ETH -> Token
-        data = ec.buildV2SwapExactETHForTokens(amountIn, toToken)
-    } else if toToken == common.HexToAddress(ec.config.WETHAddress) {
-        // Token -> ETH
-        data = ec.buildV2SwapExactTokensForETH(amountIn, fromToken)
+    var route *v2Route
+    var err error
+    if pair.ExactOutput {
+        route, err = ec.findBestV2RouteExactOut(ctx, pair.From, pair.To, pair.AmountOut)
     } else {
-        // Token -> Token
-        data = ec.buildV2SwapExactTokensForTokens(amountIn, fromToken, toToken)
+        route, err = ec.findBestV2Route(ctx, pair.From, pair.To, pair.AmountIn)
+    }
+    if err != nil {
+        return nil, err
+    }
+    hops, err := ec.v2Hops(ctx, route)
+    if err != nil {
+        return nil, err
     }
 
-    gasEstimate, err := ec.EstimateGas(ctx, ec.walletMgr.GetAddress(), &routerAddress, big.NewInt(0), data)
+    limits := newSwapLimits(pair, route.AmountIn(), route.AmountOut(), req.SlippageTolerance)
+
+    // 交易数据
+    data, value, err := ec.buildV2SwapData(pair, limits, route.Path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to build swap data: %w", err)
+    }
+
+    approval, err := ec.checkAllowance(ctx, pair, routerAddress, limits.AmountInMax)
     if err != nil {
-        return nil, fmt.Errorf("failed to estimate gas: %w", err)
+        return nil, err
+    }
+    method := approvalMethod(approval)
+    tx := &swapTransaction{To: routerAddress, Data: data, Value: value}
+
+    var gasEstimate uint64
+    if approval != nil {
+        // 钱包已授权 Permit2 时通过 Universal Router 签名授权，省去单独的 approve 交易
+        permitTx, permitGas, err := ec.buildPermit2Swap(ctx, pair, limits, approval, func(recipient common.Address) (*urCommand, error) {
+            return v2URSwap(pair, limits, route.Path, recipient)
+        })
+        if err == nil {
+            tx, gasEstimate = permitTx, permitGas
+            approval = nil
+            method = ApprovalMethodPermit2
+        } else {
+            ec.logger.Debug("Permit2 not usable, falling back to approve",
+                zap.String("token", approval.Token),
+                zap.Error(err),
+            )
+        }
+    }
+    if method != ApprovalMethodPermit2 {
+        gasEstimate, err = ec.estimateSwapGas(ctx, routerAddress, value, data, approval, v2SwapGasFallback(route))
+        if err != nil {
+            return nil, err
+        }
     }
 
     // get gas price
-    gasPrice, err := ec.GetGasPrice(ctx)
+    fees, err := ec.walletMgr.SuggestFees(ctx)
+    if err != nil {
+        return nil, err
+    }
+    gasPrice := fees.EffectiveGasPrice()
+
+    gasTokenPrice := ec.nativeTokenPrice(ctx)
+    gasCostUSD := ec.calculateGasCostUSD(gasEstimate, gasPrice, gasTokenPrice)
+
+    result := &SwapResponse{
+        FromToken:      req.FromToken,
+        ToToken:        req.ToToken,
+        GasEstimate:    gasEstimate,
+        GasPrice:       decimal.FromWei(gasPrice),
+        GasCostUSD:     gasCostUSD,
+        Slippage:       req.SlippageTolerance,
+        Router:         "Uniswap V2",
+        Hops:           hops,
+        ApprovalNeeded: approval != nil,
+        ApprovalMethod: method,
+        Approval:       approval,
+        GasFallback:    approval != nil,
+        Success:        true,
+        tx:             tx,
+    }
+    setSwapAmounts(result, pair, limits)
+    setFeeFields(result, fees)
+    setGasCostFields(result, gasTokenPrice)
+
+    midPrice, err := ec.v2MidPrice(ctx, route)
     if err != nil {
-        return nil, fmt.Errorf("failed to get gas price: %w", err)
+        ec.logger.Warn("Failed to compute V2 mid price", zap.Error(err))
     }
+    setPriceImpact(result, midPrice)
 
-    estimatedOutput := ec.estimateV2Output(ctx, fromToken, toToken, amountIn)
+    return result, nil
+}
 
-    minOutput := estimatedOutput.Mul(decimal.NewFromInt(1).Sub(req.SlippageTolerance))
+func (ec *EthereumClient) simulateUniswapV3Swap(ctx context.Context, req *SwapRequest, pair *swapPair) (*SwapResponse, error) {
+    // 精确输出只支持直连池子
+    if pair.ExactOutput {
+        quote, err := ec.estimateV3Input(ctx, pair.From, pair.To, pair.AmountOut)
+        if err != nil {
+            return nil, err
+        }
+        return ec.simulateUniswapV3SwapWithRoute(ctx, req, pair, &v3Route{
+            Path:        []common.Address{pair.From, pair.To},
+            Quotes:      []*v3Quote{quote},
+            ExactOutput: true,
+        })
+    }
 
-    gasCostUSD := ec.calculateGasCostUSD(gasEstimate, gasPrice)
+    route, err := ec.findBestV3Route(ctx, pair.From, pair.To, pair.AmountIn, false)
+    if err != nil {
+        return nil, err
+    }
+    return ec.simulateUniswapV3SwapWithRoute(ctx, req, pair, route)
+}
 
-    return &SwapResponse{
-        FromToken:       req.FromToken,
-        ToToken:         req.ToToken,
-        InputAmount:     req.Amount,
-        EstimatedOutput: estimatedOutput,
-        MinOutput:       minOutput,
-        GasEstimate:     gasEstimate,
-        GasPrice:        decimal.FromWei(gasPrice),
-        GasCostUSD:      gasCostUSD,
-        Slippage:        req.SlippageTolerance,
-        Router:          "Uniswap V2",
-        Success:         true,
-    }, nil
+// 只在指定手续费档位的直连池子上模拟
+func (ec *EthereumClient) simulateUniswapV3SwapWithFee(ctx context.Context, req *SwapRequest, pair *swapPair, fee uint32) (*SwapResponse, error) {
+    var quote *v3Quote
+    var err error
+    if pair.ExactOutput {
+        quote, err = ec.quoteV3ExactOutputSingle(ctx, pair.From, pair.To, pair.AmountOut, fee)
+    } else {
+        quote, err = ec.quoteV3ExactInputSingle(ctx, pair.From, pair.To, pair.AmountIn, fee)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("no Uniswap V3 quote at fee tier %d: %w", fee, err)
+    }
+    route := &v3Route{
+        Path:        []common.Address{pair.From, pair.To},
+        Quotes:      []*v3Quote{quote},
+        ExactOutput: pair.ExactOutput,
+    }
+    return ec.simulateUniswapV3SwapWithRoute(ctx, req, pair, route)
+}
+
+// 只考虑经过中间代币的多跳路径
+func (ec *EthereumClient) simulateUniswapV3MultiHopSwap(ctx context.Context, req *SwapRequest, pair *swapPair) (*SwapResponse, error) {
+    if pair.ExactOutput {
+        return nil, fmt.Errorf("exact output is only supported on direct Uniswap V3 pools")
+    }
+    route, err := ec.findBestV3Route(ctx, pair.From, pair.To, pair.AmountIn, true)
+    if err != nil {
+        return nil, err
+    }
+    return ec.simulateUniswapV3SwapWithRoute(ctx, req, pair, route)
 }
 
-func (ec *EthereumClient) simulateUniswapV3Swap(ctx context.Context, req *SwapRequest, fromToken, toToken common.Address) (*SwapResponse, error) {
+func (ec *EthereumClient) simulateUniswapV3SwapWithRoute(ctx context.Context, req *SwapRequest, pair *swapPair, route *v3Route) (*SwapResponse, error) {
     routerAddress := common.HexToAddress(ec.config.UniswapV3Router)
-    amountIn := decimal.ToWei(req.Amount)
 
-    data := ec.buildV3SwapData(amountIn, fromToken, toToken)
+    hops, err := ec.v3Hops(ctx, route)
+    if err != nil {
+        return nil, err
+    }
+
+    limits := newSwapLimits(pair, route.AmountIn(), route.AmountOut(), req.SlippageTolerance)
 
-    gasEstimate, err := ec.EstimateGas(ctx, ec.walletMgr.GetAddress(), &routerAddress, big.NewInt(0), data)
+    // ETH -> Token 时 router 会把发送的 ETH 包装成 WETH，精确输出时按最多输入发送
+    value := big.NewInt(0)
+    if route.Path[0] == common.HexToAddress(ec.config.WETHAddress) {
+        value = limits.AmountInMax
+    }
+
+    approval, err := ec.checkAllowance(ctx, pair, routerAddress, limits.AmountInMax)
     if err != nil {
-        return nil, fmt.Errorf("failed to estimate gas: %w", err)
+        return nil, err
+    }
+    method := approvalMethod(approval)
+
+    var tx *swapTransaction
+    var gasEstimate uint64
+    if approval != nil {
+        // 代币支持 EIP-2612 时在 multicall 中先 selfPermit，省去单独的 approve 交易
+        data, permitGas, err := ec.buildV3PermitSwap(ctx, route, pair, approval, limits, value)
+        if err == nil {
+            tx = &swapTransaction{To: routerAddress, Data: data, Value: value}
+            gasEstimate = permitGas
+            approval = nil
+            method = ApprovalMethodPermit
+        } else {
+            ec.logger.Debug("Permit not usable, trying Permit2",
+                zap.String("token", approval.Token),
+                zap.Error(err),
+            )
+        }
+    }
+    if approval != nil {
+        // 不支持 EIP-2612 但钱包已授权 Permit2 时通过 Universal Router 执行
+        permitTx, permitGas, err := ec.buildPermit2Swap(ctx, pair, limits, approval, func(recipient common.Address) (*urCommand, error) {
+            return v3URSwap(route, limits, recipient)
+        })
+        if err == nil {
+            tx, gasEstimate = permitTx, permitGas
+            approval = nil
+            method = ApprovalMethodPermit2
+        } else {
+            ec.logger.Debug("Permit2 not usable, falling back to approve",
+                zap.String("token", approval.Token),
+                zap.Error(err),
+            )
+        }
+    }
+    if tx == nil {
+        data, err := ec.buildV3SwapData(route, pair, limits, nil)
+        if err != nil {
+            return nil, fmt.Errorf("failed to build swap data: %w", err)
+        }
+        gasEstimate, err = ec.estimateSwapGas(ctx, routerAddress, value, data, approval, v3SwapGasFallback(route))
+        if err != nil {
+            return nil, err
+        }
+        tx = &swapTransaction{To: routerAddress, Data: data, Value: value}
     }
 
-    gasPrice, err := ec.GetGasPrice(ctx)
+    fees, err := ec.walletMgr.SuggestFees(ctx)
     if err != nil {
-        return nil, fmt.Errorf("failed to get gas price: %w", err)
+        return nil, err
     }
+    gasPrice := fees.EffectiveGasPrice()
+
+    gasTokenPrice := ec.nativeTokenPrice(ctx)
+    gasCostUSD := ec.calculateGasCostUSD(gasEstimate, gasPrice, gasTokenPrice)
+
+    result := &SwapResponse{
+        FromToken:      req.FromToken,
+        ToToken:        req.ToToken,
+        GasEstimate:    gasEstimate,
+        GasPrice:       decimal.FromWei(gasPrice),
+        GasCostUSD:     gasCostUSD,
+        Slippage:       req.SlippageTolerance,
+        Router:         "Uniswap V3",
+        Hops:           hops,
+        ApprovalNeeded: approval != nil,
+        ApprovalMethod: method,
+        Approval:       approval,
+        GasFallback:    approval != nil,
+        Success:        true,
+        tx:             tx,
+    }
+    setSwapAmounts(result, pair, limits)
+    setFeeFields(result, fees)
+    setGasCostFields(result, gasTokenPrice)
+    setV3RouteFields(result, route)
 
-    estimatedOutput := ec.estimateV3Output(ctx, fromToken, toToken, amountIn)
-    minOutput := estimatedOutput.Mul(decimal.NewFromInt(1).Sub(req.SlippageTolerance))
-    gasCostUSD := ec.calculateGasCostUSD(gasEstimate, gasPrice)
+    midPrice, err := ec.v3MidPrice(ctx, route)
+    if err != nil {
+        ec.logger.Warn("Failed to compute V3 mid price", zap.Error(err))
+    }
+    setPriceImpact(result, midPrice)
 
-    return &SwapResponse{
-        FromToken:       req.FromToken,
-        ToToken:         req.ToToken,
-        InputAmount:     req.Amount,
-        EstimatedOutput: estimatedOutput,
-        MinOutput:       minOutput,
-        GasEstimate:     gasEstimate,
-        GasPrice:        decimal.FromWei(gasPrice),
-        GasCostUSD:      gasCostUSD,
-        Slippage:        req.SlippageTolerance,
-        Router:          "Uniswap V3",
-        Success:         true,
-    }, nil
+    return result, nil
+}
+
+// 按输入输出是否为 ETH 和交易类型选择 router 方法，返回交易数据和需要发送的 ETH 数量
+func (ec *EthereumClient) buildV2SwapData(pair *swapPair, limits *swapLimits, path []common.Address) ([]byte, *big.Int, error) {
+    wethAddress := common.HexToAddress(ec.config.WETHAddress)
+
+    var data []byte
+    var err error
+    value := big.NewInt(0)
+    switch {
+    case pair.From == wethAddress && pair.ExactOutput:
+        data, err = ec.buildV2SwapETHForExactTokens(pair, limits.AmountOut, path)
+        value = limits.AmountInMax
+    case pair.From == wethAddress:
+        // ETH -> Token
+        data, err = ec.buildV2SwapExactETHForTokens(pair, limits.AmountOutMin, path)
+        value = limits.AmountIn
+    case pair.To == wethAddress && pair.ExactOutput:
+        data, err = ec.buildV2SwapTokensForExactETH(pair, limits.AmountOut, limits.AmountInMax, path)
+    case pair.To == wethAddress:
+        // Token -> ETH
+        data, err = ec.buildV2SwapExactTokensForETH(pair, limits.AmountIn, limits.AmountOutMin, path)
+    case pair.ExactOutput:
+        data, err = ec.buildV2SwapTokensForExactTokens(pair, limits.AmountOut, limits.AmountInMax, path)
+    default:
+        // Token -> Token
+        data, err = ec.buildV2SwapExactTokensForTokens(pair, limits.AmountIn, limits.AmountOutMin, path)
+    }
+    if err != nil {
+        return nil, nil, err
+    }
+    return data, value, nil
+}
+
+// 报价中的跨越 tick 数，单跳时直接给出池子的档位和成交后价格
+func setV3RouteFields(result *SwapResponse, route *v3Route) {
+    ticksCrossed := route.TicksCrossed()
+    result.TicksCrossed = &ticksCrossed
+    if len(route.Quotes) == 1 {
+        result.FeeTier = &route.Quotes[0].Fee
+        result.SqrtPriceAfter = stringPtr(route.Quotes[0].SqrtPriceX96After.String())
+    }
 }
 
-func (ec *EthereumClient) buildV2SwapExactETHForTokens(amountIn *big.Int, toToken common.Address) []byte {
-    // 实现 V2 ETH to Token 交易数据构建
-    return []byte{}
+func (ec *EthereumClient) buildV2SwapExactETHForTokens(pair *swapPair, amountOutMin *big.Int, path []common.Address) ([]byte, error) {
+    // ETH 数量通过交易的 value 发送
+    return uniswapV2RouterABI.Pack("swapExactETHForTokens", amountOutMin, path, pair.Recipient, pair.deadlineUnix())
 }
 
-func (ec *EthereumClient) buildV2SwapExactTokensForETH(amountIn *big.Int, fromToken common.Address) []byte {
-    // 实现 V2 Token -> ETH 交易数据构建
-    return []byte{}
+func (ec *EthereumClient) buildV2SwapExactTokensForETH(pair *swapPair, amountIn, amountOutMin *big.Int, path []common.Address) ([]byte, error) {
+    return uniswapV2RouterABI.Pack("swapExactTokensForETH", amountIn, amountOutMin, path, pair.Recipient, pair.deadlineUnix())
 }
 
-func (ec *EthereumClient) buildV2SwapExactTokensForTokens(amountIn *big.Int, fromToken, toToken common.Address) []byte {
-    // 实现 V2 Token 到 Token 交易数据构建
-    return []byte{}
+func (ec *EthereumClient) buildV2SwapExactTokensForTokens(pair *swapPair, amountIn, amountOutMin *big.Int, path []common.Address) ([]byte, error) {
+    return uniswapV2RouterABI.Pack("swapExactTokensForTokens", amountIn, amountOutMin, path, pair.Recipient, pair.deadlineUnix())
 }
 
-func (ec *EthereumClient) buildV3SwapData(amountIn *big.Int, fromToken, toToken common.Address) []byte {
-    // build V3 交易数据构建
-    return []byte{}
+// permit 不为空时作为 multicall 的第一个调用
+func (ec *EthereumClient) buildV3SwapData(route *v3Route, pair *swapPair, limits *swapLimits, permit []byte) ([]byte, error) {
+    routerAddress := common.HexToAddress(ec.config.UniswapV3Router)
+    weth := common.HexToAddress(ec.config.WETHAddress)
+    fromETH := route.Path[0] == weth
+    toETH := route.Path[len(route.Path)-1] == weth
+
+    // 输出为 ETH 时先把 WETH 留在 router，再通过 unwrapWETH9 转给接收地址
+    recipient := pair.Recipient
+    if toETH {
+        recipient = routerAddress
+    }
+
+    var swapData []byte
+    var err error
+    switch {
+    case route.ExactOutput:
+        swapData, err = uniswapV3RouterABI.Pack("exactOutputSingle", v3ExactOutputSingleParams{
+            TokenIn:           route.Path[0],
+            TokenOut:          route.Path[1],
+            Fee:               big.NewInt(int64(route.Quotes[0].Fee)),
+            Recipient:         recipient,
+            Deadline:          pair.deadlineUnix(),
+            AmountOut:         limits.AmountOut,
+            AmountInMaximum:   limits.AmountInMax,
+            SqrtPriceLimitX96: big.NewInt(0),
+        })
+    case len(route.Quotes) == 1:
+        swapData, err = uniswapV3RouterABI.Pack("exactInputSingle", v3ExactInputSingleParams{
+            TokenIn:           route.Path[0],
+            TokenOut:          route.Path[1],
+            Fee:               big.NewInt(int64(route.Quotes[0].Fee)),
+            Recipient:         recipient,
+            Deadline:          pair.deadlineUnix(),
+            AmountIn:          limits.AmountIn,
+            AmountOutMinimum:  limits.AmountOutMin,
+            SqrtPriceLimitX96: big.NewInt(0),
+        })
+    default:
+        swapData, err = uniswapV3RouterABI.Pack("exactInput", v3ExactInputParams{
+            Path:             encodeV3Path(route.Path, route.Fees()),
+            Recipient:        recipient,
+            Deadline:         pair.deadlineUnix(),
+            AmountIn:         limits.AmountIn,
+            AmountOutMinimum: limits.AmountOutMin,
+        })
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    var calls [][]byte
+    if permit != nil {
+        calls = append(calls, permit)
+    }
+    calls = append(calls, swapData)
+    if toETH {
+        unwrapData, err := uniswapV3RouterABI.Pack("unwrapWETH9", limits.AmountOutMin, pair.Recipient)
+        if err != nil {
+            return nil, err
+        }
+        calls = append(calls, unwrapData)
+    }
+    // 精确输出时 value 按最多输入发送，没用完的 ETH 需要 refundETH 退回
+    if fromETH && route.ExactOutput {
+        refundData, err := uniswapV3RouterABI.Pack("refundETH")
+        if err != nil {
+            return nil, err
+        }
+        calls = append(calls, refundData)
+    }
+    if len(calls) == 1 {
+        return swapData, nil
+    }
+    return uniswapV3RouterABI.Pack("multicall", calls)
 }
 
-func (ec *EthereumClient) estimateV2Output(ctx context.Context, fromToken, toToken common.Address, amountIn *big.Int) decimal.Decimal {
-    return decimal.NewFromFloat(0.95).Mul(decimal.FromWei(amountIn)) 
+// 签名 permit 后构建 swap 并预估 gas，预估失败说明代币的 permit 实现不可用
+func (ec *EthereumClient) buildV3PermitSwap(ctx context.Context, route *v3Route, pair *swapPair, approval *TokenApproval, limits *swapLimits, value *big.Int) ([]byte, uint64, error) {
+    permit, err := ec.buildSelfPermit(ctx, approval, pair.deadlineUnix())
+    if err != nil {
+        return nil, 0, err
+    }
+    data, err := ec.buildV3SwapData(route, pair, limits, permit)
+    if err != nil {
+        return nil, 0, err
+    }
+    routerAddress := common.HexToAddress(ec.config.UniswapV3Router)
+    gasEstimate, err := ec.EstimateGas(ctx, ec.walletMgr.GetAddress(), &routerAddress, value, data)
+    if err != nil {
+        return nil, 0, fmt.Errorf("permit swap reverted: %w", err)
+    }
+    return data, gasEstimate, nil
 }
 
-func (ec *EthereumClient) estimateV3Output(ctx context.Context, fromToken, toToken common.Address, amountIn *big.Int) decimal.Decimal {
-    return decimal.NewFromFloat(0.97).Mul(decimal.FromWei(amountIn)) 
+// 返回路径上每一跳的交易对和数量，Amounts[0] 为输入
+func (ec *EthereumClient) quoteV2Path(ctx context.Context, path []common.Address, amountIn *big.Int) (*v2Route, error) {
+    pairs, err := ec.v2Pairs(ctx, path)
+    if err != nil {
+        return nil, err
+    }
+
+    router := bind.NewBoundContract(common.HexToAddress(ec.config.UniswapV2Router), uniswapV2RouterABI, ec.caller, nil, nil)
+    var amountsOut []interface{}
+    if err := router.Call(&bind.CallOpts{Context: ctx}, &amountsOut, "getAmountsOut", amountIn, path); err != nil {
+        return nil, fmt.Errorf("failed to quote Uniswap V2 output: %w", err)
+    }
+    amounts := *abi.ConvertType(amountsOut[0], new([]*big.Int)).(*[]*big.Int)
+    if len(amounts) != len(path) {
+        return nil, fmt.Errorf("unexpected getAmountsOut result length %d", len(amounts))
+    }
+
+    return &v2Route{Path: path, Pairs: pairs, Amounts: amounts}, nil
+}
+
+// 先确认每一跳的交易对都存在，否则 router 只会返回一个含糊的 revert
+func (ec *EthereumClient) v2Pairs(ctx context.Context, path []common.Address) ([]common.Address, error) {
+    factory := bind.NewBoundContract(common.HexToAddress(ec.config.UniswapV2Factory), uniswapV2FactoryABI, ec.caller, nil, nil)
+    pairs := make([]common.Address, 0, len(path)-1)
+    for i := 0; i < len(path)-1; i++ {
+        var pairOut []interface{}
+        if err := factory.Call(&bind.CallOpts{Context: ctx}, &pairOut, "getPair", path[i], path[i+1]); err != nil {
+            return nil, fmt.Errorf("failed to query Uniswap V2 pair: %w", err)
+        }
+        pair := *abi.ConvertType(pairOut[0], new(common.Address)).(*common.Address)
+        if pair == (common.Address{}) {
+            return nil, fmt.Errorf("no Uniswap V2 pair for %s/%s", path[i].Hex(), path[i+1].Hex())
+        }
+        pairs = append(pairs, pair)
+    }
+    return pairs, nil
+}
+
+type v3Quote struct {
+    Fee               uint32
+    AmountIn          *big.Int
+    AmountOut         *big.Int
+    SqrtPriceX96After *big.Int
+    TicksCrossed      uint32
+    GasEstimate       *big.Int
+}
+
+// 遍历所有手续费档位，返回输出最多的报价
+func (ec *EthereumClient) estimateV3Output(ctx context.Context, fromToken, toToken common.Address, amountIn *big.Int) (*v3Quote, error) {
+    var best *v3Quote
+    for _, fee := range v3FeeTiers {
+        quote, err := ec.quoteV3ExactInputSingle(ctx, fromToken, toToken, amountIn, fee)
+        if err != nil {
+            // 该档位没有池子或流动性不足
+            ec.logger.Debug("V3 quote failed",
+                zap.Uint32("fee", fee),
+                zap.Error(err),
+            )
+            continue
+        }
+        if best == nil || quote.AmountOut.Cmp(best.AmountOut) > 0 {
+            best = quote
+        }
+    }
+
+    if best == nil {
+        return nil, fmt.Errorf("no Uniswap V3 pool with liquidity for %s/%s", fromToken.Hex(), toToken.Hex())
+    }
+    return best, nil
+}
+
+func (ec *EthereumClient) quoteV3ExactInputSingle(ctx context.Context, fromToken, toToken common.Address, amountIn *big.Int, fee uint32) (*v3Quote, error) {
+    quoter := bind.NewBoundContract(common.HexToAddress(ec.config.UniswapV3Quoter), uniswapV3QuoterABI, ec.caller, nil, nil)
+
+    var out []interface{}
+    err := quoter.Call(&bind.CallOpts{Context: ctx}, &out, "quoteExactInputSingle", v3QuoteExactInputSingleParams{
+        TokenIn:           fromToken,
+        TokenOut:          toToken,
+        AmountIn:          amountIn,
+        Fee:               big.NewInt(int64(fee)),
+        SqrtPriceLimitX96: big.NewInt(0),
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return &v3Quote{
+        Fee:               fee,
+        AmountIn:          amountIn,
+        AmountOut:         abi.ConvertType(out[0], new(big.Int)).(*big.Int),
+        SqrtPriceX96After: abi.ConvertType(out[1], new(big.Int)).(*big.Int),
+        TicksCrossed:      *abi.ConvertType(out[2], new(uint32)).(*uint32),
+        GasEstimate:       abi.ConvertType(out[3], new(big.Int)).(*big.Int),
+    }, nil
 }
 
-func (ec *EthereumClient) calculateGasCostUSD(gasEstimate uint64, gasPrice *big.Int) decimal.Decimal {
-    ethPrice := decimal.NewFromFloat(2000) 
-    gasCostETH := decimal.FromWei(big.NewInt(0).Mul(gasPrice, big.NewInt(int64(gasEstimate))))
-    return gasCostETH.Mul(ethPrice)
+// 按滑点计算最少输出，向下取整
+func applySlippage(amountOut *big.Int, slippage decimal.Decimal) *big.Int {
+    return decimal.NewFromBigInt(amountOut, 0).Mul(decimal.NewFromInt(1).Sub(slippage)).BigInt()
+}
+
+func swapDeadline(seconds int64) time.Time {
+    if seconds <= 0 {
+        return time.Now().Add(defaultSwapDeadline)
+    }
+    return time.Now().Add(time.Duration(seconds) * time.Second)
 }
@@ -0,0 +1,169 @@
+package ethereum
+
+import (
+    "context"
+    "errors"
+    "math"
+    "math/big"
+    "testing"
+    "time"
+
+    "github.com/ethereum/go-ethereum/accounts/abi"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/stretchr/testify/assert"
+
+    "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
+)
+
+func TestValidateSwapRequest_Deadline(t *testing.T) {
+    ec := &EthereumClient{}
+    newRequest := func(seconds int64) *SwapRequest {
+        return &SwapRequest{
+            FromToken:         "ETH",
+            ToToken:           "USDC",
+            Amount:            decimal.NewFromInt(1),
+            SlippageTolerance: decimal.NewFromFloat(0.01),
+            DeadlineSeconds:   seconds,
+        }
+    }
+
+    assert.NoError(t, ec.validateSwapRequest(newRequest(0)))
+    assert.NoError(t, ec.validateSwapRequest(newRequest(86400)))
+    assert.Error(t, ec.validateSwapRequest(newRequest(86401)))
+    assert.Error(t, ec.validateSwapRequest(newRequest(-1)))
+    // 换算成 time.Duration 会溢出为负数的值
+    assert.Error(t, ec.validateSwapRequest(newRequest(math.MaxInt64/1000)))
+}
+
+func TestBuildV2SwapData(t *testing.T) {
+    weth := common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+    usdc := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+    dai := common.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F")
+    recipient := common.HexToAddress("0x742d35Cc6634C0532925a3b8D7a2a5c4A7A6A5a5")
+    deadline := time.Unix(1700000000, 0)
+    ec := &EthereumClient{config: &EthereumConfig{WETHAddress: weth.Hex()}}
+    limits := &swapLimits{
+        AmountIn:     big.NewInt(1000),
+        AmountOut:    big.NewInt(2000),
+        AmountInMax:  big.NewInt(1000),
+        AmountOutMin: big.NewInt(1980),
+    }
+
+    tests := []struct {
+        method string
+        path   []common.Address
+        value  *big.Int
+    }{
+        {"swapExactETHForTokens", []common.Address{weth, usdc}, big.NewInt(1000)},
+        {"swapExactTokensForETH", []common.Address{usdc, weth}, big.NewInt(0)},
+        {"swapExactTokensForTokens", []common.Address{usdc, weth, dai}, big.NewInt(0)},
+    }
+    for _, tt := range tests {
+        pair := &swapPair{From: tt.path[0], To: tt.path[len(tt.path)-1], Recipient: recipient, Deadline: deadline}
+        data, value, err := ec.buildV2SwapData(pair, limits, tt.path)
+        assert.NoError(t, err, tt.method)
+        assert.Equal(t, tt.value, value, tt.method)
+
+        method, err := uniswapV2RouterABI.MethodById(data[:4])
+        assert.NoError(t, err, tt.method)
+        assert.Equal(t, tt.method, method.Name)
+        args, err := method.Inputs.Unpack(data[4:])
+        assert.NoError(t, err, tt.method)
+
+        // ETH 输入时数量通过 value 发送，参数里没有 amountIn
+        if tt.method != "swapExactETHForTokens" {
+            assert.Equal(t, limits.AmountIn, args[0], tt.method)
+            args = args[1:]
+        }
+        assert.Equal(t, limits.AmountOutMin, args[0], tt.method)
+        assert.Equal(t, tt.path, args[1], tt.method)
+        assert.Equal(t, recipient, args[2], tt.method)
+        assert.Equal(t, big.NewInt(deadline.Unix()), args[3], tt.method)
+    }
+}
+
+type fakeV3Pool struct {
+    tokenIn  common.Address
+    tokenOut common.Address
+    fee      uint32
+}
+
+// 模拟 QuoterV2.quoteExactInputSingle，没有对应池子的档位 revert
+func fakeV3Quoter(quotes map[fakeV3Pool]*v3Quote) fakeContracts {
+    return fakeContracts{
+        testV3Quoter: {abi: uniswapV3QuoterABI, methods: map[string]fakeMethod{
+            "quoteExactInputSingle": func(args []interface{}) ([]interface{}, error) {
+                params := abi.ConvertType(args[0], new(v3QuoteExactInputSingleParams)).(*v3QuoteExactInputSingleParams)
+                quote, ok := quotes[fakeV3Pool{params.TokenIn, params.TokenOut, uint32(params.Fee.Uint64())}]
+                if !ok {
+                    return nil, errors.New("execution reverted")
+                }
+                return []interface{}{quote.AmountOut, quote.SqrtPriceX96After, quote.TicksCrossed, quote.GasEstimate}, nil
+            },
+        }},
+    }
+}
+
+func TestFindBestV3Route_FeeTiers(t *testing.T) {
+    sqrtPrice, _ := new(big.Int).SetString("4339505179874779489431521", 10)
+    quote := func(out int64, ticks uint32) *v3Quote {
+        return &v3Quote{AmountOut: big.NewInt(out), SqrtPriceX96After: sqrtPrice, TicksCrossed: ticks, GasEstimate: big.NewInt(90000)}
+    }
+    // 100 档位没有池子，3000 档位输出最多
+    ec := newRouteTestClient(fakeV3Quoter(map[fakeV3Pool]*v3Quote{
+        {testWETH, testUSDC, 500}:   quote(2990000000, 1),
+        {testWETH, testUSDC, 3000}:  quote(3005000000, 2),
+        {testWETH, testUSDC, 10000}: quote(2900000000, 0),
+    }))
+    ctx := context.Background()
+    amountIn := big.NewInt(1e18)
+
+    route, err := ec.findBestV3Route(ctx, testWETH, testUSDC, amountIn, false)
+    assert.NoError(t, err)
+    assert.Equal(t, []common.Address{testWETH, testUSDC}, route.Path)
+    assert.Len(t, route.Quotes, 1)
+    assert.Equal(t, uint32(3000), route.Quotes[0].Fee)
+    assert.Equal(t, amountIn, route.AmountIn())
+    assert.Equal(t, big.NewInt(3005000000), route.AmountOut())
+
+    // 档位、成交后价格和跨越的 tick 数写入 swap 结果
+    result := &SwapResponse{}
+    setV3RouteFields(result, route)
+    assert.Equal(t, uint32(3000), *result.FeeTier)
+    assert.Equal(t, sqrtPrice.String(), *result.SqrtPriceAfter)
+    assert.Equal(t, uint32(2), *result.TicksCrossed)
+
+    // 选中的档位编码进 exactInputSingle
+    pair := &swapPair{From: testWETH, To: testUSDC, Recipient: testV2Router, Deadline: time.Unix(1700000000, 0)}
+    limits := newSwapLimits(pair, route.AmountIn(), route.AmountOut(), decimal.NewFromFloat(0.01))
+    data, err := ec.buildV3SwapData(route, pair, limits, nil)
+    assert.NoError(t, err)
+    method, err := uniswapV3RouterABI.MethodById(data[:4])
+    assert.NoError(t, err)
+    assert.Equal(t, "exactInputSingle", method.Name)
+    args, err := method.Inputs.Unpack(data[4:])
+    assert.NoError(t, err)
+    params := abi.ConvertType(args[0], new(v3ExactInputSingleParams)).(*v3ExactInputSingleParams)
+    assert.Equal(t, big.NewInt(3000), params.Fee)
+    assert.Equal(t, testWETH, params.TokenIn)
+    assert.Equal(t, testUSDC, params.TokenOut)
+    assert.Equal(t, amountIn, params.AmountIn)
+    assert.Equal(t, big.NewInt(2974950000), params.AmountOutMinimum)
+
+    _, err = ec.findBestV3Route(ctx, testDAI, testWBTC, amountIn, false)
+    assert.Error(t, err)
+    assert.Contains(t, err.Error(), "no Uniswap V3 pool with liquidity")
+}
+
+func TestSetV3RouteFields_MultiHop(t *testing.T) {
+    route := &v3Route{Quotes: []*v3Quote{
+        {Fee: 500, TicksCrossed: 1, SqrtPriceX96After: big.NewInt(1)},
+        {Fee: 3000, TicksCrossed: 3, SqrtPriceX96After: big.NewInt(2)},
+    }}
+    result := &SwapResponse{}
+    setV3RouteFields(result, route)
+    // 多跳时档位和价格在 hops 里给出
+    assert.Nil(t, result.FeeTier)
+    assert.Nil(t, result.SqrtPriceAfter)
+    assert.Equal(t, uint32(4), *result.TicksCrossed)
+}
@@ -0,0 +1,84 @@
+//代币信息
+package ethereum
+
+import (
+    "context"
+    "errors"
+    "fmt"
+
+    "github.com/ethereum/go-ethereum/accounts/abi"
+    "github.com/ethereum/go-ethereum/accounts/abi/bind"
+    "github.com/ethereum/go-ethereum/common"
+)
+
+// 批量查询代币精度，没有缓存的代币通过一次 Multicall3 调用获取
+func (ec *EthereumClient) tokensDecimals(ctx context.Context, tokens []common.Address) ([]int, error) {
+    decimals := make([]int, len(tokens))
+    var missing []int
+    ec.decimalsMu.RLock()
+    for i, token := range tokens {
+        value, ok := ec.decimalsCache[token]
+        if !ok {
+            missing = append(missing, i)
+            continue
+        }
+        decimals[i] = value
+    }
+    ec.decimalsMu.RUnlock()
+    if len(missing) == 0 {
+        return decimals, nil
+    }
+
+    calls := make([]multicall3Call, len(missing))
+    for j, i := range missing {
+        calls[j] = multicall3Call{Target: tokens[i], AllowFailure: true, CallData: erc20ABI.Methods["decimals"].ID}
+    }
+    results, err := ec.multicall.aggregate(ctx, nil, calls)
+    if errors.Is(err, errMulticallUnavailable) {
+        ec.logger.Debug("Multicall3 not available, reading decimals one by one")
+        for _, i := range missing {
+            if decimals[i], err = ec.callDecimals(ctx, tokens[i]); err != nil {
+                return nil, err
+            }
+        }
+        return decimals, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    ec.decimalsMu.Lock()
+    defer ec.decimalsMu.Unlock()
+    for j, i := range missing {
+        token := tokens[i]
+        result := results[j]
+        if !result.Success {
+            return nil, fmt.Errorf("failed to get decimals for %s: execution reverted", token.Hex())
+        }
+        if len(result.ReturnData) == 0 {
+            return nil, fmt.Errorf("failed to get decimals for %s: %w", token.Hex(), ErrNotContract)
+        }
+        out, err := erc20ABI.Unpack("decimals", result.ReturnData)
+        if err != nil {
+            return nil, fmt.Errorf("failed to decode decimals of %s: %w", token.Hex(), err)
+        }
+        decimals[i] = int(*abi.ConvertType(out[0], new(uint8)).(*uint8))
+        ec.decimalsCache[token] = decimals[i]
+    }
+    return decimals, nil
+}
+
+func (ec *EthereumClient) callDecimals(ctx context.Context, token common.Address) (int, error) {
+    caller := NewERC20Caller(token, ec.caller)
+    value, err := caller.Decimals(&bind.CallOpts{Context: ctx})
+    if err != nil {
+        return 0, fmt.Errorf("failed to get decimals for %s: %w", token.Hex(), err)
+    }
+    decimals := int(value)
+
+    ec.decimalsMu.Lock()
+    ec.decimalsCache[token] = decimals
+    ec.decimalsMu.Unlock()
+
+    return decimals, nil
+}
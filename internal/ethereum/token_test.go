@@ -0,0 +1,46 @@
+package ethereum
+
+import (
+    "context"
+    "errors"
+    "testing"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/stretchr/testify/assert"
+    "go.uber.org/zap"
+)
+
+func TestTokensDecimals_Multicall(t *testing.T) {
+    usdc := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+    wbtc := common.HexToAddress("0x2260FAC5E5542a773Aa44fBCfeDf7C193bc2C599")
+    weth := common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+    eoa := common.HexToAddress("0x742d35Cc6634C0532925a3b8D7a2a5c4A7A6A5a5")
+
+    fake := &fakeMulticall{
+        deployed: true,
+        contracts: map[common.Address]map[string][]byte{
+            usdc: {string(erc20ABI.Methods["decimals"].ID): packERC20Output(t, erc20ABI, "decimals", uint8(6))},
+            wbtc: {string(erc20ABI.Methods["decimals"].ID): packERC20Output(t, erc20ABI, "decimals", uint8(8))},
+        },
+    }
+    ec := &EthereumClient{
+        logger:        zap.NewNop(),
+        decimalsCache: map[common.Address]int{weth: 18},
+        multicall:     newMulticaller(fake, ""),
+    }
+    ctx := context.Background()
+
+    // 缓存里没有的两个代币在同一次调用中查询
+    decimals, err := ec.tokensDecimals(ctx, []common.Address{usdc, weth, wbtc})
+    assert.NoError(t, err)
+    assert.Equal(t, []int{6, 18, 8}, decimals)
+    assert.Equal(t, 1, fake.requests)
+
+    decimals, err = ec.tokensDecimals(ctx, []common.Address{wbtc, usdc})
+    assert.NoError(t, err)
+    assert.Equal(t, []int{8, 6}, decimals)
+    assert.Equal(t, 1, fake.requests)
+
+    _, err = ec.tokensDecimals(ctx, []common.Address{usdc, eoa})
+    assert.True(t, errors.Is(err, ErrNotContract))
+}
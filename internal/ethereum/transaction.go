@@ -0,0 +1,101 @@
+//交易签名和广播
+package ethereum
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+    "strings"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/core/types"
+    "go.uber.org/zap"
+
+    "github.com/your-username/ethereum-trading-mcp/internal/wallet"
+    "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
+)
+
+// gas 预估值上浮的比例，避免链上状态变化导致 out of gas
+const gasLimitBufferPercent = 20
+
+// 发出的交易按 quoteID 记录下来，之后可以加速或取消
+func (ec *EthereumClient) sendTransaction(ctx context.Context, quoteID string, to common.Address, data []byte, value *big.Int, gasEstimate uint64) (*types.Transaction, error) {
+    opts, err := ec.walletMgr.GetTransactor()
+    if err != nil {
+        return nil, err
+    }
+
+    gasLimit := gasEstimate * (100 + gasLimitBufferPercent) / 100
+
+    // GetTransactor 只在不支持 EIP-1559 的链上设置 GasPrice
+    var tx *types.Transaction
+    if opts.GasFeeCap != nil {
+        tx = types.NewTx(&types.DynamicFeeTx{
+            ChainID:   ec.walletMgr.GetChainID(),
+            Nonce:     opts.Nonce.Uint64(),
+            GasTipCap: opts.GasTipCap,
+            GasFeeCap: opts.GasFeeCap,
+            Gas:       gasLimit,
+            To:        &to,
+            Value:     value,
+            Data:      data,
+        })
+    } else {
+        tx = types.NewTx(&types.LegacyTx{
+            Nonce:    opts.Nonce.Uint64(),
+            GasPrice: opts.GasPrice,
+            Gas:      gasLimit,
+            To:       &to,
+            Value:    value,
+            Data:     data,
+        })
+    }
+
+    signedTx, err := opts.Signer(opts.From, tx)
+    if err != nil {
+        ec.walletMgr.ReleaseNonce(tx.Nonce())
+        return nil, fmt.Errorf("failed to sign transaction: %w", err)
+    }
+
+    submission, err := ec.broadcast(ctx, signedTx)
+    if err != nil {
+        ec.walletMgr.ReleaseNonce(tx.Nonce())
+        if isNonceError(err) {
+            // 本地分配的 nonce 和链上不一致，下次发送前重新同步
+            ec.walletMgr.ResetNonces()
+        }
+        return nil, fmt.Errorf("failed to send transaction: %w", err)
+    }
+
+    ec.txs.track(signedTx, quoteID, submission)
+
+    ec.logger.Info("Transaction sent",
+        zap.String("hash", signedTx.Hash().Hex()),
+        zap.Uint64("nonce", signedTx.Nonce()),
+        zap.String("to", to.Hex()),
+        zap.Uint8("type", signedTx.Type()),
+        zap.String("submission", submission),
+    )
+
+    return signedTx, nil
+}
+
+// 节点通过 RPC 返回的错误没有类型，只能按错误信息判断
+func isNonceError(err error) bool {
+    msg := strings.ToLower(err.Error())
+    return strings.Contains(msg, "nonce too low") || strings.Contains(msg, "nonce too high")
+}
+
+// 把手续费建议写入 swap 结果，GasPrice 为预计实际支付的单价 (baseFee + tip)
+func setFeeFields(resp *SwapResponse, fees *wallet.FeeSuggestion) {
+    resp.FeeModel = fees.Model()
+    if fees.Legacy {
+        return
+    }
+    baseFee := decimal.FromWei(fees.BaseFee)
+    tip := decimal.FromWei(fees.GasTipCap)
+    maxFee := decimal.FromWei(fees.GasFeeCap)
+    resp.BaseFee = &baseFee
+    resp.PriorityFee = &tip
+    resp.MaxFeePerGas = &maxFee
+}
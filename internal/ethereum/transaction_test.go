@@ -0,0 +1,16 @@
+package ethereum
+
+import (
+    "errors"
+    "fmt"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestIsNonceError(t *testing.T) {
+    assert.True(t, isNonceError(errors.New("nonce too low")))
+    assert.True(t, isNonceError(fmt.Errorf("relay rejected transaction: %w", errors.New("Nonce too high: next nonce 5, tx nonce 7"))))
+    assert.False(t, isNonceError(errors.New("replacement transaction underpriced")))
+    assert.False(t, isNonceError(errors.New("insufficient funds for gas * price + value")))
+}
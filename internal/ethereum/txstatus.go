@@ -0,0 +1,248 @@
+//交易状态查询
+package ethereum
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "math/big"
+    "time"
+
+    geth "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/accounts/abi"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/common/hexutil"
+    "github.com/ethereum/go-ethereum/core/types"
+    "github.com/ethereum/go-ethereum/rpc"
+
+    "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
+)
+
+const (
+    TxStatusPending  = "pending"
+    TxStatusMined    = "mined"
+    TxStatusFailed   = "failed"
+    TxStatusNotFound = "not_found"
+)
+
+// 等待确认时的轮询间隔和超时
+const (
+    txStatusPollInterval = 3 * time.Second
+    defaultTxWaitTimeout = 60 * time.Second
+    maxTxWaitTimeout     = 10 * time.Minute
+)
+
+type TransactionStatusRequest struct {
+    TxHash string `json:"tx_hash"`
+    // 大于 0 时阻塞直到达到该确认数、交易失败或超时
+    WaitConfirmations uint64 `json:"wait_confirmations"`
+    TimeoutSeconds    int64  `json:"timeout_seconds"`
+}
+
+type TransactionStatusResponse struct {
+    TxHash            string           `json:"tx_hash"`
+    Status            string           `json:"status"`
+    From              string           `json:"from,omitempty"`
+    To                string           `json:"to,omitempty"`
+    Nonce             *uint64          `json:"nonce,omitempty"`
+    BlockNumber       *uint64          `json:"block_number,omitempty"`
+    Confirmations     uint64           `json:"confirmations"`
+    GasUsed           *uint64          `json:"gas_used,omitempty"`
+    EffectiveGasPrice *decimal.Decimal `json:"effective_gas_price,omitempty"`
+    FeePaid           *decimal.Decimal `json:"fee_paid,omitempty"`
+    RevertReason      *string          `json:"revert_reason,omitempty"`
+    TimedOut          bool             `json:"timed_out,omitempty"`
+    Submission        string           `json:"submission,omitempty"`
+    // 交易已被 speed_up_transaction 或 cancel_transaction 替换时，替换链中最新的交易及其状态
+    ReplacedBy *string                    `json:"replaced_by,omitempty"`
+    Latest     *TransactionStatusResponse `json:"latest,omitempty"`
+    Success    bool                       `json:"success"`
+    Error      *string                    `json:"error,omitempty"`
+}
+
+func (ec *EthereumClient) GetTransactionStatus(ctx context.Context, req *TransactionStatusRequest) (*TransactionStatusResponse, error) {
+    hash, err := parseTxHash(req.TxHash)
+    if err != nil {
+        return &TransactionStatusResponse{
+            TxHash:  req.TxHash,
+            Success: false,
+            Error:   stringPtr(err.Error()),
+        }, nil
+    }
+
+    status, err := ec.transactionStatus(ctx, hash)
+    if err != nil || req.WaitConfirmations == 0 {
+        return status, err
+    }
+
+    timeout := defaultTxWaitTimeout
+    if req.TimeoutSeconds > 0 {
+        timeout = time.Duration(req.TimeoutSeconds) * time.Second
+    }
+    if timeout > maxTxWaitTimeout {
+        timeout = maxTxWaitTimeout
+    }
+    waitCtx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+
+    ticker := time.NewTicker(txStatusPollInterval)
+    defer ticker.Stop()
+    for !txWaitDone(status, req.WaitConfirmations) {
+        select {
+        case <-waitCtx.Done():
+            status.TimedOut = true
+            return status, nil
+        case <-ticker.C:
+        }
+
+        // 用外层 ctx 查询，避免超时打断最后一次查询
+        next, err := ec.transactionStatus(ctx, hash)
+        if err != nil {
+            return nil, err
+        }
+        status = next
+    }
+    return status, nil
+}
+
+// 交易失败或者达到要求的确认数时结束等待；交易被替换时看最新的替换交易
+func txWaitDone(status *TransactionStatusResponse, confirmations uint64) bool {
+    switch status.Status {
+    case TxStatusFailed:
+        return true
+    case TxStatusMined:
+        return status.Confirmations >= confirmations
+    }
+    if status.Latest != nil {
+        return txWaitDone(status.Latest, confirmations)
+    }
+    return false
+}
+
+// 查询交易状态，交易已被替换且自身没有上链时一并查询替换链中最新交易的状态
+func (ec *EthereumClient) transactionStatus(ctx context.Context, hash common.Hash) (*TransactionStatusResponse, error) {
+    resp, err := ec.singleTransactionStatus(ctx, hash)
+    if err != nil {
+        return nil, err
+    }
+
+    latest, ok := ec.txs.latestHash(hash)
+    if !ok || latest == hash {
+        return resp, nil
+    }
+    resp.ReplacedBy = stringPtr(latest.Hex())
+    if resp.Status == TxStatusMined || resp.Status == TxStatusFailed {
+        return resp, nil
+    }
+
+    resp.Latest, err = ec.singleTransactionStatus(ctx, latest)
+    if err != nil {
+        return nil, err
+    }
+    return resp, nil
+}
+
+func (ec *EthereumClient) singleTransactionStatus(ctx context.Context, hash common.Hash) (*TransactionStatusResponse, error) {
+    resp := &TransactionStatusResponse{
+        TxHash:  hash.Hex(),
+        Success: true,
+    }
+
+    tx, isPending, err := ec.client.TransactionByHash(ctx, hash)
+    if errors.Is(err, geth.NotFound) {
+        // 节点没有这笔交易，可能已被丢弃或还没有广播到该节点；提交到私有中继的交易上链前不会出现在公开交易池
+        resp.Status = TxStatusNotFound
+        if ec.txs.submission(hash) == SubmissionRelay && ec.txs.isLatest(hash) {
+            resp.Status = TxStatusPending
+            resp.Submission = SubmissionRelay
+        }
+        return resp, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get transaction: %w", err)
+    }
+
+    from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+    if err == nil {
+        resp.From = from.Hex()
+    }
+    if tx.To() != nil {
+        resp.To = tx.To().Hex()
+    }
+    nonce := tx.Nonce()
+    resp.Nonce = &nonce
+
+    if isPending {
+        resp.Status = TxStatusPending
+        return resp, nil
+    }
+
+    receipt, err := ec.client.TransactionReceipt(ctx, hash)
+    if errors.Is(err, geth.NotFound) {
+        // 刚打包的交易，节点可能还没有索引 receipt
+        resp.Status = TxStatusPending
+        return resp, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get transaction receipt: %w", err)
+    }
+
+    latest, err := ec.client.BlockNumber(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get block number: %w", err)
+    }
+
+    blockNumber := receipt.BlockNumber.Uint64()
+    gasUsed := receipt.GasUsed
+    resp.BlockNumber = &blockNumber
+    resp.GasUsed = &gasUsed
+    if latest >= blockNumber {
+        resp.Confirmations = latest - blockNumber + 1
+    }
+    if receipt.EffectiveGasPrice != nil {
+        price := decimal.FromWei(receipt.EffectiveGasPrice)
+        fee := decimal.FromWei(new(big.Int).Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(gasUsed)))
+        resp.EffectiveGasPrice = &price
+        resp.FeePaid = &fee
+    }
+
+    if receipt.Status == types.ReceiptStatusSuccessful {
+        resp.Status = TxStatusMined
+        return resp, nil
+    }
+
+    resp.Status = TxStatusFailed
+    if from != (common.Address{}) {
+        resp.RevertReason = ec.revertReason(ctx, tx, from, receipt.BlockNumber)
+    }
+    return resp, nil
+}
+
+// 在交易所在区块的前一个区块状态上重放调用，解析 revert 原因
+func (ec *EthereumClient) revertReason(ctx context.Context, tx *types.Transaction, from common.Address, blockNumber *big.Int) *string {
+    msg := geth.CallMsg{
+        From:  from,
+        To:    tx.To(),
+        Gas:   tx.Gas(),
+        Value: tx.Value(),
+        Data:  tx.Data(),
+    }
+    parent := new(big.Int).Sub(blockNumber, big.NewInt(1))
+    _, err := ec.client.CallContract(ctx, msg, parent)
+    if err == nil {
+        // 重放成功说明失败依赖于同区块内之前的交易，或者是 out of gas
+        return stringPtr("transaction reverted (reason unavailable, possibly out of gas)")
+    }
+
+    var dataErr rpc.DataError
+    if errors.As(err, &dataErr) {
+        if hexData, ok := dataErr.ErrorData().(string); ok {
+            if data, decodeErr := hexutil.Decode(hexData); decodeErr == nil {
+                if reason, unpackErr := abi.UnpackRevert(data); unpackErr == nil {
+                    return stringPtr(reason)
+                }
+            }
+        }
+    }
+    return stringPtr(err.Error())
+}
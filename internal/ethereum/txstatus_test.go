@@ -0,0 +1,34 @@
+package ethereum
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestTxWaitDone(t *testing.T) {
+    assert.False(t, txWaitDone(&TransactionStatusResponse{Status: TxStatusPending}, 1))
+    assert.False(t, txWaitDone(&TransactionStatusResponse{Status: TxStatusNotFound}, 1))
+
+    mined := &TransactionStatusResponse{Status: TxStatusMined, Confirmations: 2}
+    assert.True(t, txWaitDone(mined, 1))
+    assert.True(t, txWaitDone(mined, 2))
+    assert.False(t, txWaitDone(mined, 3))
+
+    // 失败的交易不会再有变化，不用等确认数
+    assert.True(t, txWaitDone(&TransactionStatusResponse{Status: TxStatusFailed, Confirmations: 1}, 12))
+}
+
+func TestTxWaitDone_Replaced(t *testing.T) {
+    // 原交易已被替换，等待的是替换交易的确认数
+    status := &TransactionStatusResponse{
+        Status:     TxStatusNotFound,
+        ReplacedBy: stringPtr("0x02"),
+        Latest:     &TransactionStatusResponse{Status: TxStatusMined, Confirmations: 1},
+    }
+    assert.True(t, txWaitDone(status, 1))
+    assert.False(t, txWaitDone(status, 3))
+
+    status.Latest = &TransactionStatusResponse{Status: TxStatusPending}
+    assert.False(t, txWaitDone(status, 1))
+}
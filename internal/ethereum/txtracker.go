@@ -0,0 +1,144 @@
+//已发送交易及其替换链的记录
+package ethereum
+
+import (
+    "sync"
+    "time"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+    TxKindOriginal = "original"
+    TxKindSpeedUp  = "speed_up"
+    TxKindCancel   = "cancel"
+)
+
+// 替换链中的一笔交易，同一个 nonce 下只有一笔会上链
+type TxReplacement struct {
+    TxHash     string    `json:"tx_hash"`
+    Kind       string    `json:"kind"`
+    Submission string    `json:"submission,omitempty"`
+    SentAt     time.Time `json:"sent_at"`
+}
+
+// 原始交易和它的所有替换交易，按原始交易哈希记录
+type trackedTx struct {
+    // 同一笔交易的替换需要串行，否则后发的替换可能手续费不够
+    mu sync.Mutex
+
+    original common.Hash
+    quoteID  string
+    latest   *types.Transaction
+    chain    []TxReplacement
+}
+
+type txTracker struct {
+    mu sync.Mutex
+    // 原始交易和替换交易的哈希都指向同一条记录
+    byHash map[common.Hash]*trackedTx
+}
+
+func newTxTracker() *txTracker {
+    return &txTracker{
+        byHash: make(map[common.Hash]*trackedTx),
+    }
+}
+
+func (t *txTracker) track(tx *types.Transaction, quoteID, submission string) *trackedTx {
+    entry := &trackedTx{
+        original: tx.Hash(),
+        quoteID:  quoteID,
+        latest:   tx,
+        chain: []TxReplacement{{
+            TxHash:     tx.Hash().Hex(),
+            Kind:       TxKindOriginal,
+            Submission: submission,
+            SentAt:     time.Now(),
+        }},
+    }
+
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    t.byHash[tx.Hash()] = entry
+    return entry
+}
+
+func (t *txTracker) lookup(hash common.Hash) (*trackedTx, bool) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    entry, ok := t.byHash[hash]
+    return entry, ok
+}
+
+// 调用方需要持有 entry.mu，latest 和 chain 同时在 t.mu 下修改，不持有 entry.mu 时通过 t 读取
+func (t *txTracker) replace(entry *trackedTx, tx *types.Transaction, kind, submission string) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    entry.latest = tx
+    entry.chain = append(entry.chain, TxReplacement{
+        TxHash:     tx.Hash().Hex(),
+        Kind:       kind,
+        Submission: submission,
+        SentAt:     time.Now(),
+    })
+    t.byHash[tx.Hash()] = entry
+}
+
+func (t *txTracker) replacements(entry *trackedTx) []TxReplacement {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    return append([]TxReplacement(nil), entry.chain...)
+}
+
+// 交易是否仍是替换链中最新的一笔，未记录的交易视为最新
+func (t *txTracker) isLatest(hash common.Hash) bool {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    entry, ok := t.byHash[hash]
+    return !ok || entry.latest.Hash() == hash
+}
+
+// 哈希所在替换链中最新的一笔交易，未记录的交易返回 false
+func (t *txTracker) latestHash(hash common.Hash) (common.Hash, bool) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    entry, ok := t.byHash[hash]
+    if !ok {
+        return common.Hash{}, false
+    }
+    return entry.latest.Hash(), true
+}
+
+func (t *txTracker) submission(hash common.Hash) string {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    if item := t.item(hash); item != nil {
+        return item.Submission
+    }
+    return ""
+}
+
+func (t *txTracker) setSubmission(hash common.Hash, submission string) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    if item := t.item(hash); item != nil {
+        item.Submission = submission
+    }
+}
+
+// 调用方需要持有 t.mu
+func (t *txTracker) item(hash common.Hash) *TxReplacement {
+    entry, ok := t.byHash[hash]
+    if !ok {
+        return nil
+    }
+    for i := range entry.chain {
+        if entry.chain[i].TxHash == hash.Hex() {
+            return &entry.chain[i]
+        }
+    }
+    return nil
+}
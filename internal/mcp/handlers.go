@@ -5,6 +5,7 @@ import (
     "encoding/json"
     "fmt"
     "strconv"
+    "time"
 
     "go.uber.org/zap"
 
@@ -37,11 +38,19 @@ func (h *MCPHandler) registerTools() {
                 "properties": map[string]interface{}{
                     "address": map[string]interface{}{
                         "type":        "string",
-                        "description": "Ethereum wallet address",
+                        "description": "Ethereum wallet address or ENS name (e.g., vitalik.eth)",
                     },
                     "token_address": map[string]interface{}{
                         "type":        "string",
-                        "description": "Optional ERC20 token contract address",
+                        "description": "Optional ERC20 token contract address or ENS name",
+                    },
+                    "block_number": map[string]interface{}{
+                        "type":        "integer",
+                        "description": "Optional block number to query the historical balance at",
+                    },
+                    "timestamp": map[string]interface{}{
+                        "type":        []string{"integer", "string"},
+                        "description": "Optional time (unix seconds or RFC3339) to query the balance at; resolves to the last block at or before it",
                     },
                 },
                 "required": []string{"address"},
@@ -55,7 +64,7 @@ func (h *MCPHandler) registerTools() {
                 "properties": map[string]interface{}{
                     "token_identifier": map[string]interface{}{
                         "type":        "string",
-                        "description": "Token address or symbol (e.g., 'ETH', 'USDC', '0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48')",
+                        "description": "Token address, ENS name or symbol (e.g., 'ETH', 'USDC', '0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48')",
                     },
                 },
                 "required": []string{"token_identifier"},
@@ -63,21 +72,21 @@ func (h *MCPHandler) registerTools() {
         },
         {
             Name:        "swap_tokens",
-            Description: "Simulate a token swap on Uniswap V2 or V3",
+            Description: "Simulate a token swap on Uniswap V2 or V3, picking the best route net of gas by default",
             InputSchema: map[string]interface{}{
                 "type": "object",
                 "properties": map[string]interface{}{
                     "from_token": map[string]interface{}{
                         "type":        "string",
-                        "description": "Source token address or symbol",
+                        "description": "Source token address, ENS name or symbol",
                     },
                     "to_token": map[string]interface{}{
                         "type":        "string",
-                        "description": "Destination token address or symbol",
+                        "description": "Destination token address, ENS name or symbol",
                     },
                     "amount": map[string]interface{}{
                         "type":        "string",
-                        "description": "Amount to swap (as string to preserve precision)",
+                        "description": "Amount to swap (as string to preserve precision); the exact output amount when trade_type is 'exact_output'",
                     },
                     "slippage_tolerance": map[string]interface{}{
                         "type":        "string",
@@ -85,12 +94,132 @@ func (h *MCPHandler) registerTools() {
                     },
                     "use_v3": map[string]interface{}{
                         "type":        "boolean",
-                        "description": "Whether to use Uniswap V3 (defaults to V2)",
+                        "description": "Explicitly use Uniswap V3 (true) or V2 (false), overriding routing",
+                    },
+                    "routing": map[string]interface{}{
+                        "type":        "string",
+                        "enum":        []string{ethereum.RoutingAuto, ethereum.RoutingV2, ethereum.RoutingV3},
+                        "description": "Routing mode: 'auto' quotes V2 and every V3 fee tier and picks the best (default)",
+                    },
+                    "trade_type": map[string]interface{}{
+                        "type":        "string",
+                        "enum":        []string{ethereum.TradeTypeExactInput, ethereum.TradeTypeExactOutput},
+                        "description": "'exact_input' sells exactly amount (default); 'exact_output' buys exactly amount, with slippage applied as a maximum input",
+                    },
+                    "deadline_seconds": map[string]interface{}{
+                        "type":        "integer",
+                        "description": "Seconds until the router rejects the swap (default: 1200, max: 86400)",
+                    },
+                    "recipient": map[string]interface{}{
+                        "type":        "string",
+                        "description": "Address or ENS name that receives the output tokens (default: the server wallet)",
                     },
                 },
                 "required": []string{"from_token", "to_token", "amount"},
             },
         },
+        {
+            Name:        "execute_swap",
+            Description: "Execute a swap previously simulated with swap_tokens: re-quotes it, then signs and broadcasts if the live quote is still within slippage tolerance",
+            InputSchema: map[string]interface{}{
+                "type": "object",
+                "properties": map[string]interface{}{
+                    "quote_id": map[string]interface{}{
+                        "type":        "string",
+                        "description": "quote_id returned by swap_tokens (must not be expired)",
+                    },
+                },
+                "required": []string{"quote_id"},
+            },
+        },
+        {
+            Name:        "approve_token",
+            Description: "Approve the router to spend the input token of a swap_tokens quote that reported approval_needed",
+            InputSchema: map[string]interface{}{
+                "type": "object",
+                "properties": map[string]interface{}{
+                    "quote_id": map[string]interface{}{
+                        "type":        "string",
+                        "description": "quote_id returned by swap_tokens (must not be expired)",
+                    },
+                    "mode": map[string]interface{}{
+                        "type":        "string",
+                        "enum":        []string{"exact", "unlimited"},
+                        "description": "Approve exactly the quoted input amount, or an unlimited allowance (default: exact)",
+                        "default":     "exact",
+                    },
+                },
+                "required": []string{"quote_id"},
+            },
+        },
+        {
+            Name:        "get_transaction_status",
+            Description: "Get the status of a sent transaction: pending/mined/failed, confirmations, gas used, effective gas price and revert reason. Optionally waits for a confirmation depth",
+            InputSchema: map[string]interface{}{
+                "type": "object",
+                "properties": map[string]interface{}{
+                    "tx_hash": map[string]interface{}{
+                        "type":        "string",
+                        "description": "Transaction hash",
+                    },
+                    "wait_confirmations": map[string]interface{}{
+                        "type":        "integer",
+                        "description": "If set, block until the transaction has this many confirmations, fails, or the timeout passes",
+                    },
+                    "timeout_seconds": map[string]interface{}{
+                        "type":        "integer",
+                        "description": "Maximum time to wait in seconds (default: 60, max: 600)",
+                    },
+                },
+                "required": []string{"tx_hash"},
+            },
+        },
+        {
+            Name:        "get_portfolio",
+            Description: "Get ETH and all non-zero ERC20 balances of an address with USD/ETH value, portfolio totals and percentage allocation",
+            InputSchema: map[string]interface{}{
+                "type": "object",
+                "properties": map[string]interface{}{
+                    "address": map[string]interface{}{
+                        "type":        "string",
+                        "description": "Wallet address or ENS name",
+                    },
+                    "tokens": map[string]interface{}{
+                        "type":        "array",
+                        "items":       map[string]interface{}{"type": "string"},
+                        "description": "Token symbols, contract addresses or ENS names to include (default: the configured watchlist)",
+                    },
+                },
+                "required": []string{"address"},
+            },
+        },
+        {
+            Name:        "speed_up_transaction",
+            Description: "Re-send a pending transaction with the same nonce and higher fees so it gets mined sooner",
+            InputSchema: replaceTransactionSchema(),
+        },
+        {
+            Name:        "cancel_transaction",
+            Description: "Cancel a pending transaction by replacing its nonce with a zero-value transfer to the wallet itself at higher fees",
+            InputSchema: replaceTransactionSchema(),
+        },
+    }
+}
+
+func replaceTransactionSchema() map[string]interface{} {
+    return map[string]interface{}{
+        "type": "object",
+        "properties": map[string]interface{}{
+            "tx_hash": map[string]interface{}{
+                "type":        "string",
+                "description": "Hash of the pending transaction, or of any earlier replacement of it",
+            },
+            "fee_bump_percent": map[string]interface{}{
+                "type":        "integer",
+                "description": "Percent to raise the fees over the last sent transaction (default: 15, min: 10)",
+            },
+        },
+        "required": []string{"tx_hash"},
     }
 }
 
@@ -131,6 +260,18 @@ func (h *MCPHandler) HandleCallTool(params *CallToolParams) (*ToolResult, error)
         return h.handleGetTokenPrice(params.Arguments)
     case "swap_tokens":
         return h.handleSwapTokens(params.Arguments)
+    case "execute_swap":
+        return h.handleExecuteSwap(params.Arguments)
+    case "approve_token":
+        return h.handleApproveToken(params.Arguments)
+    case "get_transaction_status":
+        return h.handleGetTransactionStatus(params.Arguments)
+    case "get_portfolio":
+        return h.handleGetPortfolio(params.Arguments)
+    case "speed_up_transaction":
+        return h.handleReplaceTransaction(params.Arguments, h.ethClient.SpeedUpTransaction)
+    case "cancel_transaction":
+        return h.handleReplaceTransaction(params.Arguments, h.ethClient.CancelTransaction)
     default:
         return nil, fmt.Errorf("unknown tool: %s", params.Name)
     }
@@ -147,8 +288,13 @@ func (h *MCPHandler) handleGetBalance(args map[string]interface{}) (*ToolResult,
         tokenAddress = &tokenAddr
     }
 
+    at, err := parseBlockSelector(args)
+    if err != nil {
+        return nil, err
+    }
+
     ctx := context.Background()
-    balance, err := h.ethClient.GetBalance(ctx, address, tokenAddress)
+    balance, err := h.ethClient.GetBalanceAt(ctx, address, tokenAddress, at)
     if err != nil {
         return &ToolResult{
             Content: []ToolContent{
@@ -170,7 +316,7 @@ func (h *MCPHandler) handleGetBalance(args map[string]interface{}) (*ToolResult,
         Content: []ToolContent{
             {
                 Type: "text",
-                Text: fmt.Sprintf("Balance for %s:", address),
+                Text: balanceLabel(balance),
             },
             {
                 Type: "text",
@@ -220,57 +366,167 @@ func (h *MCPHandler) handleGetTokenPrice(args map[string]interface{}) (*ToolResu
 }
 
 func (h *MCPHandler) handleSwapTokens(args map[string]interface{}) (*ToolResult, error) {
-    fromToken, ok := args["from_token"].(string)
-    if !ok {
-        return nil, fmt.Errorf("from_token is required and must be a string")
+    req, err := parseSwapRequest(args)
+    if err != nil {
+        return nil, err
     }
 
-    toToken, ok := args["to_token"].(string)
-    if !ok {
-        return nil, fmt.Errorf("to_token is required and must be a string")
+    ctx := context.Background()
+    result, err := h.ethClient.SwapTokens(ctx, req)
+    if err != nil {
+        return &ToolResult{
+            Content: []ToolContent{
+                {
+                    Type: "text",
+                    Text: fmt.Sprintf("Error simulating swap: %v", err),
+                },
+            },
+            IsError: true,
+        }, nil
     }
 
-    amountStr, ok := args["amount"].(string)
-    if !ok {
-        return nil, fmt.Errorf("amount is required and must be a string")
+    resultJSON, err := json.MarshalIndent(result, "", "  ")
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal swap result: %w", err)
     }
 
-    amount, err := decimal.ParseDecimal(amountStr)
-    if err != nil {
-        return nil, fmt.Errorf("invalid amount format: %w", err)
+    var text string
+    if result.Success {
+        gasCost := "~$" + result.GasCostUSD.StringFixed(2)
+        switch result.GasCostSource {
+        case ethereum.GasPriceSourceUnavailable:
+            gasCost = fmt.Sprintf("unavailable (no %s price)", result.GasToken)
+        case ethereum.GasPriceSourceCached, ethereum.GasPriceSourceStale:
+            // 不是实时价格时标出来源
+            gasCost += fmt.Sprintf(" (%s %s price)", result.GasCostSource, result.GasToken)
+        }
+        text = fmt.Sprintf("Swap simulation successful:\nInput: %s %s\nEstimated Output: %s %s\nGas Cost: %s\nRoute: %s",
+            result.InputAmount.String(), result.FromToken,
+            result.EstimatedOutput.String(), result.ToToken,
+            gasCost,
+            routeLabel(result))
+        if result.Recipient != "" {
+            text += fmt.Sprintf("\nRecipient: %s", addressLabel(result.Recipient, result.RecipientName))
+        }
+        if result.MaxInput != nil {
+            text += fmt.Sprintf("\nMax Input: %s %s", result.MaxInput.String(), result.FromToken)
+        }
+        if result.BaseFee != nil && result.PriorityFee != nil {
+            text += fmt.Sprintf("\nGas Fee: base %s + tip %s gwei", toGwei(*result.BaseFee), toGwei(*result.PriorityFee))
+        }
+        if result.PriceImpact != nil {
+            text += fmt.Sprintf("\nPrice Impact: %s%%", result.PriceImpact.StringFixed(2))
+        }
+        if result.ImpactWarning != nil {
+            text += fmt.Sprintf("\nWarning: %s", *result.ImpactWarning)
+        }
+        for _, warning := range result.AddressWarnings {
+            text += fmt.Sprintf("\nWarning: %s", warning)
+        }
+        switch result.ApprovalMethod {
+        case ethereum.ApprovalMethodPermit:
+            text += "\nApproval: signed EIP-2612 permit, no approve transaction needed"
+        case ethereum.ApprovalMethodPermit2:
+            text += "\nApproval: signed Permit2 permit via Universal Router, no approve transaction needed"
+        }
+        if result.ApprovalNeeded {
+            text += fmt.Sprintf("\nApproval needed: allowance %s, required %s for %s (gas is an estimate until approved)",
+                result.Approval.Allowance.String(), result.Approval.Required.String(), result.Approval.Spender)
+        }
+        if result.QuoteID != "" {
+            text += fmt.Sprintf("\nQuote ID: %s (block %d, expires %s)",
+                result.QuoteID, result.BlockNumber, result.ExpiresAt.Format(time.RFC3339))
+        }
+    } else {
+        text = fmt.Sprintf("Swap simulation failed: %s", *result.Error)
     }
 
-    slippageStr := "0.01" // 默认 1% 滑点
-    if s, ok := args["slippage_tolerance"].(string); ok {
-        slippageStr = s
+    return &ToolResult{
+        Content: []ToolContent{
+            {
+                Type: "text",
+                Text: text,
+            },
+            {
+                Type: "text",
+                Text: string(resultJSON),
+            },
+        },
+    }, nil
+}
+
+func (h *MCPHandler) handleExecuteSwap(args map[string]interface{}) (*ToolResult, error) {
+    quoteID, ok := args["quote_id"].(string)
+    if !ok || quoteID == "" {
+        return nil, fmt.Errorf("quote_id is required and must be a string")
     }
 
-    slippage, err := decimal.ParseDecimal(slippageStr)
+    req := &ethereum.ExecuteSwapRequest{
+        QuoteID: quoteID,
+    }
+
+    ctx := context.Background()
+    result, err := h.ethClient.ExecuteSwap(ctx, req)
     if err != nil {
-        return nil, fmt.Errorf("invalid slippage_tolerance format: %w", err)
+        return &ToolResult{
+            Content: []ToolContent{
+                {
+                    Type: "text",
+                    Text: fmt.Sprintf("Error executing swap: %v", err),
+                },
+            },
+            IsError: true,
+        }, nil
     }
 
-    useV3 := false
-    if v3, ok := args["use_v3"].(bool); ok {
-        useV3 = v3
+    resultJSON, err := json.MarshalIndent(result, "", "  ")
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal execute result: %w", err)
     }
 
-    req := &ethereum.SwapRequest{
-        FromToken:       fromToken,
-        ToToken:         toToken,
-        Amount:          amount,
-        SlippageTolerance: slippage,
-        UseV3:           useV3,
+    var text string
+    if result.Success {
+        text = fmt.Sprintf("Swap submitted:\nTx Hash: %s\nNonce: %d\nSubmission: %s", result.TxHash, result.Nonce, result.Submission)
+    } else {
+        text = fmt.Sprintf("Swap not executed: %s", *result.Error)
+    }
+
+    return &ToolResult{
+        Content: []ToolContent{
+            {
+                Type: "text",
+                Text: text,
+            },
+            {
+                Type: "text",
+                Text: string(resultJSON),
+            },
+        },
+        IsError: !result.Success,
+    }, nil
+}
+
+func (h *MCPHandler) handleApproveToken(args map[string]interface{}) (*ToolResult, error) {
+    quoteID, ok := args["quote_id"].(string)
+    if !ok || quoteID == "" {
+        return nil, fmt.Errorf("quote_id is required and must be a string")
+    }
+
+    req := &ethereum.ApproveTokenRequest{
+        QuoteID: quoteID,
+    }
+    if mode, ok := args["mode"].(string); ok {
+        req.Mode = mode
     }
 
     ctx := context.Background()
-    result, err := h.ethClient.SwapTokens(ctx, req)
+    result, err := h.ethClient.ApproveToken(ctx, req)
     if err != nil {
         return &ToolResult{
             Content: []ToolContent{
                 {
                     Type: "text",
-                    Text: fmt.Sprintf("Error simulating swap: %v", err),
+                    Text: fmt.Sprintf("Error approving token: %v", err),
                 },
             },
             IsError: true,
@@ -279,17 +535,14 @@ func (h *MCPHandler) handleSwapTokens(args map[string]interface{}) (*ToolResult,
 
     resultJSON, err := json.MarshalIndent(result, "", "  ")
     if err != nil {
-        return nil, fmt.Errorf("failed to marshal swap result: %w", err)
+        return nil, fmt.Errorf("failed to marshal approve result: %w", err)
     }
 
     var text string
     if result.Success {
-        text = fmt.Sprintf("Swap simulation successful:\nInput: %s %s\nEstimated Output: %s %s\nGas Cost: ~$%s",
-            result.InputAmount.String(), result.FromToken,
-            result.EstimatedOutput.String(), result.ToToken,
-            result.GasCostUSD.StringFixed(2))
+        text = fmt.Sprintf("Approval submitted:\nAmount: %s\nTx Hash: %s\nNonce: %d\nSubmission: %s", result.Amount, result.TxHash, result.Nonce, result.Submission)
     } else {
-        text = fmt.Sprintf("Swap simulation failed: %s", *result.Error)
+        text = fmt.Sprintf("Approval not sent: %s", *result.Error)
     }
 
     return &ToolResult{
@@ -303,5 +556,365 @@ func (h *MCPHandler) handleSwapTokens(args map[string]interface{}) (*ToolResult,
                 Text: string(resultJSON),
             },
         },
+        IsError: !result.Success,
     }, nil
 }
+
+func (h *MCPHandler) handleGetTransactionStatus(args map[string]interface{}) (*ToolResult, error) {
+    txHash, ok := args["tx_hash"].(string)
+    if !ok || txHash == "" {
+        return nil, fmt.Errorf("tx_hash is required and must be a string")
+    }
+
+    req := &ethereum.TransactionStatusRequest{
+        TxHash: txHash,
+    }
+    if c, ok := args["wait_confirmations"].(float64); ok {
+        if c < 0 || c != float64(uint64(c)) {
+            return nil, fmt.Errorf("wait_confirmations must be a non-negative integer")
+        }
+        req.WaitConfirmations = uint64(c)
+    }
+    if t, ok := args["timeout_seconds"].(float64); ok {
+        if t <= 0 || t != float64(int64(t)) {
+            return nil, fmt.Errorf("timeout_seconds must be a positive integer")
+        }
+        req.TimeoutSeconds = int64(t)
+    }
+
+    ctx := context.Background()
+    result, err := h.ethClient.GetTransactionStatus(ctx, req)
+    if err != nil {
+        return &ToolResult{
+            Content: []ToolContent{
+                {
+                    Type: "text",
+                    Text: fmt.Sprintf("Error getting transaction status: %v", err),
+                },
+            },
+            IsError: true,
+        }, nil
+    }
+
+    resultJSON, err := json.MarshalIndent(result, "", "  ")
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal transaction status: %w", err)
+    }
+
+    var text string
+    if !result.Success {
+        text = fmt.Sprintf("Transaction status unavailable: %s", *result.Error)
+    } else {
+        text = fmt.Sprintf("Transaction %s: %s", result.TxHash, result.Status)
+        if result.BlockNumber != nil {
+            text += fmt.Sprintf("\nBlock: %d (%d confirmations)", *result.BlockNumber, result.Confirmations)
+        }
+        if result.RevertReason != nil {
+            text += fmt.Sprintf("\nRevert Reason: %s", *result.RevertReason)
+        }
+        if result.ReplacedBy != nil {
+            text += fmt.Sprintf("\nReplaced By: %s", *result.ReplacedBy)
+        }
+        if result.Latest != nil {
+            text += fmt.Sprintf("\nLatest Replacement: %s", result.Latest.Status)
+            if result.Latest.BlockNumber != nil {
+                text += fmt.Sprintf(" in block %d (%d confirmations)", *result.Latest.BlockNumber, result.Latest.Confirmations)
+            }
+        }
+        if result.TimedOut {
+            text += fmt.Sprintf("\nTimed out waiting for %d confirmations", req.WaitConfirmations)
+        }
+    }
+
+    return &ToolResult{
+        Content: []ToolContent{
+            {
+                Type: "text",
+                Text: text,
+            },
+            {
+                Type: "text",
+                Text: string(resultJSON),
+            },
+        },
+        IsError: !result.Success,
+    }, nil
+}
+
+func (h *MCPHandler) handleGetPortfolio(args map[string]interface{}) (*ToolResult, error) {
+    address, ok := args["address"].(string)
+    if !ok || address == "" {
+        return nil, fmt.Errorf("address is required and must be a string")
+    }
+
+    req := &ethereum.PortfolioRequest{
+        Address: address,
+    }
+    if tokens, ok := args["tokens"].([]interface{}); ok {
+        for _, token := range tokens {
+            symbol, ok := token.(string)
+            if !ok {
+                return nil, fmt.Errorf("tokens must be an array of strings")
+            }
+            req.Tokens = append(req.Tokens, symbol)
+        }
+    }
+
+    ctx := context.Background()
+    result, err := h.ethClient.GetPortfolio(ctx, req)
+    if err != nil {
+        return &ToolResult{
+            Content: []ToolContent{
+                {
+                    Type: "text",
+                    Text: fmt.Sprintf("Error getting portfolio: %v", err),
+                },
+            },
+            IsError: true,
+        }, nil
+    }
+
+    resultJSON, err := json.MarshalIndent(result, "", "  ")
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal portfolio: %w", err)
+    }
+
+    var text string
+    if !result.Success {
+        text = fmt.Sprintf("Portfolio unavailable: %s", *result.Error)
+    } else {
+        text = fmt.Sprintf("Portfolio for %s:\nTotal: $%s (%s ETH)", addressLabel(result.Address, result.AddressName), result.TotalUSD.StringFixed(2), result.TotalETH.StringFixed(6))
+        for _, holding := range result.Holdings {
+            text += fmt.Sprintf("\n%s: %s", holding.Symbol, holding.Balance.String())
+            switch {
+            case holding.ValueUSD == nil:
+                text += " (price unavailable)"
+            case holding.Allocation != nil:
+                text += fmt.Sprintf(" ($%s, %s%%)", holding.ValueUSD.StringFixed(2), holding.Allocation.StringFixed(2))
+            default:
+                text += fmt.Sprintf(" ($%s)", holding.ValueUSD.StringFixed(2))
+            }
+        }
+    }
+
+    return &ToolResult{
+        Content: []ToolContent{
+            {
+                Type: "text",
+                Text: text,
+            },
+            {
+                Type: "text",
+                Text: string(resultJSON),
+            },
+        },
+        IsError: !result.Success,
+    }, nil
+}
+
+func (h *MCPHandler) handleReplaceTransaction(args map[string]interface{}, replace func(context.Context, *ethereum.ReplaceTransactionRequest) (*ethereum.ReplaceTransactionResponse, error)) (*ToolResult, error) {
+    txHash, ok := args["tx_hash"].(string)
+    if !ok || txHash == "" {
+        return nil, fmt.Errorf("tx_hash is required and must be a string")
+    }
+
+    req := &ethereum.ReplaceTransactionRequest{
+        TxHash: txHash,
+    }
+    if b, ok := args["fee_bump_percent"].(float64); ok {
+        if b != float64(int64(b)) {
+            return nil, fmt.Errorf("fee_bump_percent must be an integer")
+        }
+        req.FeeBumpPercent = int64(b)
+    }
+
+    ctx := context.Background()
+    result, err := replace(ctx, req)
+    if err != nil {
+        return &ToolResult{
+            Content: []ToolContent{
+                {
+                    Type: "text",
+                    Text: fmt.Sprintf("Error replacing transaction: %v", err),
+                },
+            },
+            IsError: true,
+        }, nil
+    }
+
+    resultJSON, err := json.MarshalIndent(result, "", "  ")
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal replace result: %w", err)
+    }
+
+    var text string
+    if !result.Success {
+        text = fmt.Sprintf("Replacement not sent: %s", *result.Error)
+    } else {
+        text = fmt.Sprintf("Replacement (%s) submitted:\nTx Hash: %s\nReplaces: %s\nOriginal: %s\nNonce: %d\nSubmission: %s",
+            result.Kind, result.TxHash, result.ReplacedTxHash, result.OriginalTxHash, result.Nonce, result.Submission)
+        if result.MaxFeePerGas != nil {
+            text += fmt.Sprintf("\nGas Fee: max %s gwei, tip %s gwei", toGwei(*result.MaxFeePerGas), toGwei(*result.PriorityFee))
+        } else if result.GasPrice != nil {
+            text += fmt.Sprintf("\nGas Price: %s gwei", toGwei(*result.GasPrice))
+        }
+    }
+
+    return &ToolResult{
+        Content: []ToolContent{
+            {
+                Type: "text",
+                Text: text,
+            },
+            {
+                Type: "text",
+                Text: string(resultJSON),
+            },
+        },
+        IsError: !result.Success,
+    }, nil
+}
+
+// block_number 和 timestamp 都没有传入时返回 nil
+func parseBlockSelector(args map[string]interface{}) (*ethereum.BlockSelector, error) {
+    var at ethereum.BlockSelector
+    if b, ok := args["block_number"].(float64); ok {
+        if b < 0 || b != float64(uint64(b)) {
+            return nil, fmt.Errorf("block_number must be a non-negative integer")
+        }
+        number := uint64(b)
+        at.BlockNumber = &number
+    }
+
+    switch ts := args["timestamp"].(type) {
+    case nil:
+    case float64:
+        if ts < 0 || ts != float64(int64(ts)) {
+            return nil, fmt.Errorf("timestamp must be non-negative unix seconds")
+        }
+        t := time.Unix(int64(ts), 0)
+        at.Timestamp = &t
+    case string:
+        t, err := time.Parse(time.RFC3339, ts)
+        if err != nil {
+            return nil, fmt.Errorf("timestamp must be unix seconds or RFC3339: %w", err)
+        }
+        at.Timestamp = &t
+    default:
+        return nil, fmt.Errorf("timestamp must be unix seconds or RFC3339")
+    }
+
+    if at.BlockNumber != nil && at.Timestamp != nil {
+        return nil, fmt.Errorf("block_number and timestamp are mutually exclusive")
+    }
+    if at.BlockNumber == nil && at.Timestamp == nil {
+        return nil, nil
+    }
+    return &at, nil
+}
+
+func balanceLabel(balance *ethereum.BalanceResponse) string {
+    address := addressLabel(balance.Address, balance.AddressName)
+    if balance.BlockNumber == nil {
+        return fmt.Sprintf("Balance for %s:", address)
+    }
+    return fmt.Sprintf("Balance for %s at block %d (%s):", address, *balance.BlockNumber, balance.BlockTimestamp.Format(time.RFC3339))
+}
+
+// 有 ENS 名称时显示为 "name (0x...)"
+func addressLabel(address string, name *string) string {
+    if name == nil {
+        return address
+    }
+    return fmt.Sprintf("%s (%s)", *name, address)
+}
+
+func parseSwapRequest(args map[string]interface{}) (*ethereum.SwapRequest, error) {
+    fromToken, ok := args["from_token"].(string)
+    if !ok {
+        return nil, fmt.Errorf("from_token is required and must be a string")
+    }
+
+    toToken, ok := args["to_token"].(string)
+    if !ok {
+        return nil, fmt.Errorf("to_token is required and must be a string")
+    }
+
+    amountStr, ok := args["amount"].(string)
+    if !ok {
+        return nil, fmt.Errorf("amount is required and must be a string")
+    }
+
+    amount, err := decimal.ParseDecimal(amountStr)
+    if err != nil {
+        return nil, fmt.Errorf("invalid amount format: %w", err)
+    }
+
+    slippageStr := "0.01" // 默认 1% 滑点
+    if s, ok := args["slippage_tolerance"].(string); ok {
+        slippageStr = s
+    }
+
+    slippage, err := decimal.ParseDecimal(slippageStr)
+    if err != nil {
+        return nil, fmt.Errorf("invalid slippage_tolerance format: %w", err)
+    }
+
+    routing := ethereum.RoutingAuto
+    if r, ok := args["routing"].(string); ok && r != "" {
+        routing = r
+    }
+
+    // 显式传入 use_v3 时覆盖 routing
+    useV3 := false
+    if v3, ok := args["use_v3"].(bool); ok {
+        useV3 = v3
+        if !v3 {
+            routing = ethereum.RoutingV2
+        }
+    }
+
+    tradeType := ethereum.TradeTypeExactInput
+    if t, ok := args["trade_type"].(string); ok && t != "" {
+        tradeType = t
+    }
+
+    var deadlineSeconds int64
+    if d, ok := args["deadline_seconds"].(float64); ok {
+        if d != float64(int64(d)) {
+            return nil, fmt.Errorf("deadline_seconds must be an integer")
+        }
+        deadlineSeconds = int64(d)
+    }
+
+    var recipient string
+    if r, ok := args["recipient"].(string); ok {
+        recipient = r
+    }
+
+    req := &ethereum.SwapRequest{
+        FromToken:         fromToken,
+        ToToken:           toToken,
+        Amount:            amount,
+        SlippageTolerance: slippage,
+        UseV3:             useV3,
+        Routing:           routing,
+        TradeType:         tradeType,
+        DeadlineSeconds:   deadlineSeconds,
+        Recipient:         recipient,
+    }
+
+    return req, nil
+}
+
+func routeLabel(result *ethereum.SwapResponse) string {
+    if result.FeeTier != nil {
+        return fmt.Sprintf("%s (fee %d)", result.Router, *result.FeeTier)
+    }
+    return result.Router
+}
+
+// ETH 单位的手续费转换成 gwei 显示
+func toGwei(amount decimal.Decimal) string {
+    return amount.Shift(9).StringFixed(2)
+}
@@ -0,0 +1,127 @@
+//EIP-1559 手续费估算
+package wallet
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+    "sort"
+
+    geth "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+    // 参考最近多少个区块的优先费
+    feeHistoryBlocks = 10
+    // 取每个区块优先费的中位数
+    feeHistoryRewardPercentile = 50
+    // maxFee = baseFee * 倍数 + tip，可以承受连续几个满区块的 base fee 上涨
+    baseFeeMultiplier = 2
+)
+
+const (
+    FeeModelEIP1559 = "eip1559"
+    FeeModelLegacy  = "legacy"
+)
+
+// 交易手续费建议，Legacy 为 true 时只有 GasPrice 有效
+type FeeSuggestion struct {
+    Legacy    bool
+    BaseFee   *big.Int
+    GasTipCap *big.Int
+    GasFeeCap *big.Int
+    GasPrice  *big.Int
+}
+
+func (f *FeeSuggestion) Model() string {
+    if f.Legacy {
+        return FeeModelLegacy
+    }
+    return FeeModelEIP1559
+}
+
+// 预计实际支付的单价: EIP-1559 下为 baseFee + tip，不会超过 maxFee
+func (f *FeeSuggestion) EffectiveGasPrice() *big.Int {
+    if f.Legacy {
+        return f.GasPrice
+    }
+    price := new(big.Int).Add(f.BaseFee, f.GasTipCap)
+    if price.Cmp(f.GasFeeCap) > 0 {
+        return new(big.Int).Set(f.GasFeeCap)
+    }
+    return price
+}
+
+// 查询手续费相关的链上数据，ethclient.Client 实现了该接口
+type FeeSource interface {
+    HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+    SuggestGasPrice(ctx context.Context) (*big.Int, error)
+    SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+    FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*geth.FeeHistory, error)
+}
+
+// 链上有 base fee (已经过 London 升级) 时根据 eth_feeHistory 计算 tip 和 maxFee，否则使用 legacy gas price
+func (wm *WalletManager) SuggestFees(ctx context.Context) (*FeeSuggestion, error) {
+    return suggestFees(ctx, wm.client)
+}
+
+func suggestFees(ctx context.Context, source FeeSource) (*FeeSuggestion, error) {
+    header, err := source.HeaderByNumber(ctx, nil)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get latest header: %w", err)
+    }
+
+    if header.BaseFee == nil {
+        gasPrice, err := source.SuggestGasPrice(ctx)
+        if err != nil {
+            return nil, fmt.Errorf("failed to get gas price: %w", err)
+        }
+        return &FeeSuggestion{Legacy: true, GasPrice: gasPrice}, nil
+    }
+
+    history, err := source.FeeHistory(ctx, feeHistoryBlocks, nil, []float64{feeHistoryRewardPercentile})
+    if err != nil {
+        return nil, fmt.Errorf("failed to get fee history: %w", err)
+    }
+
+    // BaseFee 的最后一项是下一个区块的 base fee
+    baseFee := header.BaseFee
+    if len(history.BaseFee) > 0 {
+        baseFee = history.BaseFee[len(history.BaseFee)-1]
+    }
+
+    tip := medianReward(history.Reward)
+    if tip == nil {
+        tip, err = source.SuggestGasTipCap(ctx)
+        if err != nil {
+            return nil, fmt.Errorf("failed to get gas tip: %w", err)
+        }
+    }
+
+    feeCap := new(big.Int).Mul(baseFee, big.NewInt(baseFeeMultiplier))
+    feeCap.Add(feeCap, tip)
+
+    return &FeeSuggestion{
+        BaseFee:   baseFee,
+        GasTipCap: tip,
+        GasFeeCap: feeCap,
+    }, nil
+}
+
+// 各区块优先费的中位数，空区块的 0 不参与计算
+func medianReward(rewards [][]*big.Int) *big.Int {
+    var tips []*big.Int
+    for _, blockRewards := range rewards {
+        if len(blockRewards) > 0 && blockRewards[0] != nil && blockRewards[0].Sign() > 0 {
+            tips = append(tips, blockRewards[0])
+        }
+    }
+    if len(tips) == 0 {
+        return nil
+    }
+    sort.Slice(tips, func(i, j int) bool {
+        return tips[i].Cmp(tips[j]) < 0
+    })
+    return new(big.Int).Set(tips[len(tips)/2])
+}
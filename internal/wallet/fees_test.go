@@ -0,0 +1,96 @@
+package wallet
+
+import (
+    "context"
+    "math/big"
+    "testing"
+
+    geth "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/core/types"
+    "github.com/stretchr/testify/assert"
+)
+
+type fakeFeeSource struct {
+    baseFee  *big.Int
+    history  *geth.FeeHistory
+    gasPrice *big.Int
+    tipCap   *big.Int
+}
+
+func (f *fakeFeeSource) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+    return &types.Header{Number: big.NewInt(100), BaseFee: f.baseFee}, nil
+}
+
+func (f *fakeFeeSource) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+    return f.gasPrice, nil
+}
+
+func (f *fakeFeeSource) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+    return f.tipCap, nil
+}
+
+func (f *fakeFeeSource) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*geth.FeeHistory, error) {
+    return f.history, nil
+}
+
+func gwei(n int64) *big.Int {
+    return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e9))
+}
+
+func TestSuggestFees_EIP1559(t *testing.T) {
+    source := &fakeFeeSource{
+        baseFee: gwei(20),
+        history: &geth.FeeHistory{
+            // 最后一项是下一个区块的 base fee
+            BaseFee: []*big.Int{gwei(20), gwei(22), gwei(25)},
+            // 空区块的 0 不参与中位数
+            Reward: [][]*big.Int{{gwei(1)}, {gwei(3)}, {big.NewInt(0)}, {gwei(2)}},
+        },
+    }
+
+    fees, err := suggestFees(context.Background(), source)
+    assert.NoError(t, err)
+    assert.False(t, fees.Legacy)
+    assert.Equal(t, FeeModelEIP1559, fees.Model())
+    assert.Equal(t, gwei(25), fees.BaseFee)
+    assert.Equal(t, gwei(2), fees.GasTipCap)
+    // maxFee = 2 * baseFee + tip
+    assert.Equal(t, gwei(52), fees.GasFeeCap)
+    assert.Equal(t, gwei(27), fees.EffectiveGasPrice())
+}
+
+func TestSuggestFees_TipFallback(t *testing.T) {
+    source := &fakeFeeSource{
+        baseFee: gwei(10),
+        history: &geth.FeeHistory{Reward: [][]*big.Int{{big.NewInt(0)}, {big.NewInt(0)}}},
+        tipCap:  gwei(1),
+    }
+
+    fees, err := suggestFees(context.Background(), source)
+    assert.NoError(t, err)
+    // fee history 中没有 base fee 时使用最新区块的
+    assert.Equal(t, gwei(10), fees.BaseFee)
+    assert.Equal(t, gwei(1), fees.GasTipCap)
+    assert.Equal(t, gwei(21), fees.GasFeeCap)
+}
+
+func TestSuggestFees_Legacy(t *testing.T) {
+    fees, err := suggestFees(context.Background(), &fakeFeeSource{gasPrice: gwei(30)})
+    assert.NoError(t, err)
+    assert.True(t, fees.Legacy)
+    assert.Equal(t, FeeModelLegacy, fees.Model())
+    assert.Equal(t, gwei(30), fees.EffectiveGasPrice())
+}
+
+func TestFeeSuggestion_EffectiveGasPriceCapped(t *testing.T) {
+    fees := &FeeSuggestion{BaseFee: gwei(50), GasTipCap: gwei(2), GasFeeCap: gwei(40)}
+    assert.Equal(t, gwei(40), fees.EffectiveGasPrice())
+}
+
+func TestMedianReward(t *testing.T) {
+    assert.Nil(t, medianReward(nil))
+    assert.Nil(t, medianReward([][]*big.Int{{big.NewInt(0)}, {}}))
+    assert.Equal(t, gwei(3), medianReward([][]*big.Int{{gwei(5)}, {gwei(1)}, {gwei(3)}}))
+    // 偶数个时取较大的一个
+    assert.Equal(t, gwei(3), medianReward([][]*big.Int{{gwei(4)}, {gwei(1)}, {gwei(3)}, {gwei(2)}}))
+}
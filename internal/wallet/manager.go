@@ -8,6 +8,7 @@ import (
 
     "github.com/ethereum/go-ethereum/accounts/abi/bind"
     "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/core/types"
     "github.com/ethereum/go-ethereum/crypto"
     "github.com/ethereum/go-ethereum/ethclient"
     "go.uber.org/zap"
@@ -20,6 +21,7 @@ type WalletManager struct {
     client     *ethclient.Client
     chainID    *big.Int
     logger     *zap.Logger
+    nonces     *NonceManager
 }
 
 type WalletConfig struct {
@@ -63,6 +65,7 @@ func NewWalletManager(cfg *WalletConfig, logger *zap.Logger) (*WalletManager, er
         client:     client,
         chainID:    chainID,
         logger:     logger,
+        nonces:     NewNonceManager(client),
     }, nil
 }
 
@@ -70,31 +73,68 @@ func (wm *WalletManager) GetAddress() common.Address {
     return wm.address
 }
 
+func (wm *WalletManager) GetChainID() *big.Int {
+    return wm.chainID
+}
+
 func (wm *WalletManager) GetTransactor() (*bind.TransactOpts, error) {
     transactor, err := bind.NewKeyedTransactorWithChainID(wm.privateKey, wm.chainID)
     if err != nil {
         return nil, fmt.Errorf("failed to create transactor: %w", err)
     }
 
-    // 获取当前 nonce
-    nonce, err := wm.client.PendingNonceAt(context.Background(), wm.address)
+    // London 之后使用 EIP-1559 的 tip/maxFee，否则使用 legacy gas price
+    fees, err := wm.SuggestFees(context.Background())
     if err != nil {
-        return nil, fmt.Errorf("failed to get nonce: %w", err)
+        return nil, err
+    }
+    if fees.Legacy {
+        transactor.GasPrice = fees.GasPrice
+    } else {
+        transactor.GasTipCap = fees.GasTipCap
+        transactor.GasFeeCap = fees.GasFeeCap
     }
-    transactor.Nonce = big.NewInt(int64(nonce))
 
-    // get gas price
-    gasPrice, err := wm.client.SuggestGasPrice(context.Background())
+    // 从本地 nonce 管理器分配，交易没有发出时需要调用 ReleaseNonce
+    nonce, err := wm.nonces.Allocate(context.Background(), wm.address)
     if err != nil {
-        return nil, fmt.Errorf("failed to get gas price: %w", err)
+        return nil, err
     }
-    transactor.GasPrice = gasPrice
+    transactor.Nonce = new(big.Int).SetUint64(nonce)
 
     transactor.Context = context.Background()
 
     return transactor, nil
 }
 
+// 交易签名或广播失败时归还 GetTransactor 分配的 nonce
+func (wm *WalletManager) ReleaseNonce(nonce uint64) {
+    wm.nonces.Release(wm.address, nonce)
+}
+
+// 私有中继提交的交易在回退为公开广播之前保留其 nonce
+func (wm *WalletManager) HoldNonce(nonce uint64) {
+    wm.nonces.Hold(wm.address, nonce)
+}
+
+func (wm *WalletManager) UnholdNonce(nonce uint64) {
+    wm.nonces.Unhold(wm.address, nonce)
+}
+
+// 节点返回 nonce 错误时调用，下次分配前和链上重新同步
+func (wm *WalletManager) ResetNonces() {
+    wm.nonces.Reset(wm.address)
+}
+
+// 按交易自带的 nonce 签名，不经过 nonce 管理器，用于替换已发出的交易
+func (wm *WalletManager) SignTx(tx *types.Transaction) (*types.Transaction, error) {
+    signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(wm.chainID), wm.privateKey)
+    if err != nil {
+        return nil, fmt.Errorf("failed to sign transaction: %w", err)
+    }
+    return signedTx, nil
+}
+
 func (wm *WalletManager) GetCallOpts() *bind.CallOpts {
     return &bind.CallOpts{
         Context: context.Background(),
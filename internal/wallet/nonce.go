@@ -0,0 +1,192 @@
+//nonce 管理
+package wallet
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+    "sync"
+    "time"
+
+    "github.com/ethereum/go-ethereum/common"
+)
+
+// 分配后超过这个时间仍不在节点的 pending 中，认为交易已被丢弃
+const nonceStaleAfter = time.Minute
+
+// 查询链上 nonce，ethclient.Client 实现了该接口
+type NonceSource interface {
+    PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+    NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
+// 本地分配 nonce，避免并发发送的交易拿到相同的 nonce
+type NonceManager struct {
+    source NonceSource
+    now    func() time.Time
+
+    mu       sync.Mutex
+    accounts map[common.Address]*accountNonces
+}
+
+type accountNonces struct {
+    mu       sync.Mutex
+    next     uint64
+    synced   bool
+    inFlight map[uint64]time.Time
+    // 通过私有中继提交的交易不会出现在节点的 pending 中，持有期间不按超时回收
+    held map[uint64]int
+}
+
+func NewNonceManager(source NonceSource) *NonceManager {
+    return &NonceManager{
+        source:   source,
+        now:      time.Now,
+        accounts: make(map[common.Address]*accountNonces),
+    }
+}
+
+func (nm *NonceManager) account(addr common.Address) *accountNonces {
+    nm.mu.Lock()
+    defer nm.mu.Unlock()
+
+    acct, ok := nm.accounts[addr]
+    if !ok {
+        acct = &accountNonces{
+            inFlight: make(map[uint64]time.Time),
+            held:     make(map[uint64]int),
+        }
+        nm.accounts[addr] = acct
+    }
+    return acct
+}
+
+// 分配下一个 nonce，同一账户的分配是串行的
+func (nm *NonceManager) Allocate(ctx context.Context, addr common.Address) (uint64, error) {
+    acct := nm.account(addr)
+    acct.mu.Lock()
+    defer acct.mu.Unlock()
+
+    if err := nm.sync(ctx, addr, acct); err != nil {
+        return 0, err
+    }
+
+    // 跳过仍在使用中的 nonce (重新同步后 next 可能回退到它们之前)
+    for {
+        if _, used := acct.inFlight[acct.next]; !used {
+            break
+        }
+        acct.next++
+    }
+
+    nonce := acct.next
+    acct.inFlight[nonce] = nm.now()
+    acct.next++
+    return nonce, nil
+}
+
+// 交易没有发送成功时归还 nonce
+func (nm *NonceManager) Release(addr common.Address, nonce uint64) {
+    acct := nm.account(addr)
+    acct.mu.Lock()
+    defer acct.mu.Unlock()
+
+    delete(acct.inFlight, nonce)
+    if nonce+1 == acct.next {
+        acct.next = nonce
+        return
+    }
+    // 中间出现空洞，下次分配时和链上重新同步
+    acct.synced = false
+}
+
+// 广播返回 nonce too low / nonce too high 后强制下次分配时重新同步
+func (nm *NonceManager) Reset(addr common.Address) {
+    acct := nm.account(addr)
+    acct.mu.Lock()
+    defer acct.mu.Unlock()
+    acct.synced = false
+}
+
+// 交易通过私有中继提交后持有 nonce，直到交易上链或改为公开广播后调用 Unhold
+func (nm *NonceManager) Hold(addr common.Address, nonce uint64) {
+    acct := nm.account(addr)
+    acct.mu.Lock()
+    defer acct.mu.Unlock()
+    acct.held[nonce]++
+}
+
+// 同一 nonce 的替换交易各自持有一次，全部释放后才恢复超时回收
+func (nm *NonceManager) Unhold(addr common.Address, nonce uint64) {
+    acct := nm.account(addr)
+    acct.mu.Lock()
+    defer acct.mu.Unlock()
+
+    acct.held[nonce]--
+    if acct.held[nonce] <= 0 {
+        delete(acct.held, nonce)
+        // 从释放时开始重新计算超时，给公开广播的交易进入 pending 的时间
+        if _, ok := acct.inFlight[nonce]; ok {
+            acct.inFlight[nonce] = nm.now()
+        }
+    }
+}
+
+// 已分配但还没有被打包的 nonce
+func (nm *NonceManager) InFlight(addr common.Address) []uint64 {
+    acct := nm.account(addr)
+    acct.mu.Lock()
+    defer acct.mu.Unlock()
+
+    nonces := make([]uint64, 0, len(acct.inFlight))
+    for nonce := range acct.inFlight {
+        nonces = append(nonces, nonce)
+    }
+    return nonces
+}
+
+// 调用方需持有 acct.mu
+func (nm *NonceManager) sync(ctx context.Context, addr common.Address, acct *accountNonces) error {
+    pending, err := nm.source.PendingNonceAt(ctx, addr)
+    if err != nil {
+        return fmt.Errorf("failed to get pending nonce: %w", err)
+    }
+    mined, err := nm.source.NonceAt(ctx, addr, nil)
+    if err != nil {
+        return fmt.Errorf("failed to get nonce: %w", err)
+    }
+
+    // 已打包的 nonce 不再跟踪
+    for nonce := range acct.inFlight {
+        if nonce < mined {
+            delete(acct.inFlight, nonce)
+        }
+    }
+    for nonce := range acct.held {
+        if nonce < mined {
+            delete(acct.held, nonce)
+        }
+    }
+
+    // 节点的 pending 中没有、且分配已久的 nonce 说明交易被丢弃，从 pending nonce 重新分配
+    now := nm.now()
+    for nonce, allocatedAt := range acct.inFlight {
+        if acct.held[nonce] > 0 {
+            continue
+        }
+        if nonce >= pending && now.Sub(allocatedAt) > nonceStaleAfter {
+            delete(acct.inFlight, nonce)
+            acct.synced = false
+        }
+    }
+
+    if !acct.synced {
+        acct.next = pending
+        acct.synced = true
+    }
+    // 其他程序用同一账户发送了交易
+    if pending > acct.next {
+        acct.next = pending
+    }
+    return nil
+}
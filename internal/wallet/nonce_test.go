@@ -0,0 +1,153 @@
+package wallet
+
+import (
+    "context"
+    "math/big"
+    "sort"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/stretchr/testify/assert"
+)
+
+// 模拟链上的 nonce 状态
+type fakeNonceSource struct {
+    mu      sync.Mutex
+    pending uint64
+    mined   uint64
+}
+
+func (f *fakeNonceSource) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    return f.pending, nil
+}
+
+func (f *fakeNonceSource) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    return f.mined, nil
+}
+
+var testAccount = common.HexToAddress("0x742d35Cc6634C0532925a3b8D7a2a5c4A7A6A5a5")
+
+func TestNonceManager_Sequential(t *testing.T) {
+    source := &fakeNonceSource{pending: 5, mined: 5}
+    nm := NewNonceManager(source)
+    ctx := context.Background()
+
+    // 节点 pending 还没更新时也不会重复分配
+    for want := uint64(5); want < 8; want++ {
+        nonce, err := nm.Allocate(ctx, testAccount)
+        assert.NoError(t, err)
+        assert.Equal(t, want, nonce)
+    }
+    assert.Len(t, nm.InFlight(testAccount), 3)
+
+    // 打包后不再跟踪
+    source.mined = 7
+    source.pending = 8
+    nonce, err := nm.Allocate(ctx, testAccount)
+    assert.NoError(t, err)
+    assert.Equal(t, uint64(8), nonce)
+    inFlight := nm.InFlight(testAccount)
+    sort.Slice(inFlight, func(i, j int) bool { return inFlight[i] < inFlight[j] })
+    assert.Equal(t, []uint64{7, 8}, inFlight)
+}
+
+func TestNonceManager_Concurrent(t *testing.T) {
+    nm := NewNonceManager(&fakeNonceSource{})
+    ctx := context.Background()
+
+    var wg sync.WaitGroup
+    var mu sync.Mutex
+    seen := make(map[uint64]bool)
+    for i := 0; i < 50; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            nonce, err := nm.Allocate(ctx, testAccount)
+            assert.NoError(t, err)
+            mu.Lock()
+            defer mu.Unlock()
+            assert.False(t, seen[nonce], "nonce %d allocated twice", nonce)
+            seen[nonce] = true
+        }()
+    }
+    wg.Wait()
+    assert.Len(t, seen, 50)
+}
+
+func TestNonceManager_Release(t *testing.T) {
+    source := &fakeNonceSource{pending: 10, mined: 10}
+    nm := NewNonceManager(source)
+    ctx := context.Background()
+
+    // 归还最后一个 nonce 后直接复用
+    nonce, _ := nm.Allocate(ctx, testAccount)
+    assert.Equal(t, uint64(10), nonce)
+    nm.Release(testAccount, nonce)
+    nonce, _ = nm.Allocate(ctx, testAccount)
+    assert.Equal(t, uint64(10), nonce)
+
+    // 中间的 nonce 发送失败，重新同步后先填补空洞，再跳过仍在使用中的 nonce
+    second, _ := nm.Allocate(ctx, testAccount)
+    assert.Equal(t, uint64(11), second)
+    nm.Release(testAccount, nonce)
+
+    nonce, _ = nm.Allocate(ctx, testAccount)
+    assert.Equal(t, uint64(10), nonce)
+    nonce, _ = nm.Allocate(ctx, testAccount)
+    assert.Equal(t, uint64(12), nonce)
+}
+
+func TestNonceManager_DroppedTransaction(t *testing.T) {
+    source := &fakeNonceSource{pending: 3, mined: 3}
+    nm := NewNonceManager(source)
+    now := time.Now()
+    nm.now = func() time.Time { return now }
+    ctx := context.Background()
+
+    nonce, _ := nm.Allocate(ctx, testAccount)
+    assert.Equal(t, uint64(3), nonce)
+    nonce, _ = nm.Allocate(ctx, testAccount)
+    assert.Equal(t, uint64(4), nonce)
+
+    // 两笔交易都没有进入节点的 pending，超时后从链上的 pending nonce 重新分配
+    now = now.Add(2 * nonceStaleAfter)
+    nonce, err := nm.Allocate(ctx, testAccount)
+    assert.NoError(t, err)
+    assert.Equal(t, uint64(3), nonce)
+    assert.Equal(t, []uint64{3}, nm.InFlight(testAccount))
+}
+
+func TestNonceManager_HeldNonce(t *testing.T) {
+    source := &fakeNonceSource{pending: 3, mined: 3}
+    nm := NewNonceManager(source)
+    now := time.Now()
+    nm.now = func() time.Time { return now }
+    ctx := context.Background()
+
+    nonce, _ := nm.Allocate(ctx, testAccount)
+    assert.Equal(t, uint64(3), nonce)
+    nm.Hold(testAccount, nonce)
+
+    // 私有中继的交易不在节点的 pending 中，持有期间不会被当作丢弃
+    now = now.Add(2 * nonceStaleAfter)
+    nonce, err := nm.Allocate(ctx, testAccount)
+    assert.NoError(t, err)
+    assert.Equal(t, uint64(4), nonce)
+    nm.Release(testAccount, nonce)
+
+    // 释放后重新计时，超时仍未进入 pending 才回收
+    nm.Unhold(testAccount, 3)
+    nonce, _ = nm.Allocate(ctx, testAccount)
+    assert.Equal(t, uint64(4), nonce)
+    nm.Release(testAccount, nonce)
+
+    now = now.Add(2 * nonceStaleAfter)
+    nonce, _ = nm.Allocate(ctx, testAccount)
+    assert.Equal(t, uint64(3), nonce)
+}
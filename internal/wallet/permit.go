@@ -0,0 +1,158 @@
+//EIP-712 签名授权
+package wallet
+
+import (
+    "fmt"
+    "math/big"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/common/math"
+    "github.com/ethereum/go-ethereum/crypto"
+    "github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// EIP-2612 permit 的参数
+type ERC2612Permit struct {
+    TokenName    string
+    TokenVersion string
+    Token        common.Address
+    Spender      common.Address
+    Value        *big.Int
+    Nonce        *big.Int
+    Deadline     *big.Int
+}
+
+// Uniswap Permit2 PermitSingle 的参数，Amount 为 uint160，Expiration/Nonce 为 uint48
+type Permit2Single struct {
+    Permit2     common.Address
+    Token       common.Address
+    Spender     common.Address
+    Amount      *big.Int
+    Expiration  *big.Int
+    Nonce       *big.Int
+    SigDeadline *big.Int
+}
+
+type PermitSignature struct {
+    V         uint8
+    R         [32]byte
+    S         [32]byte
+    Signature []byte
+}
+
+var eip712DomainType = []apitypes.Type{
+    {Name: "name", Type: "string"},
+    {Name: "version", Type: "string"},
+    {Name: "chainId", Type: "uint256"},
+    {Name: "verifyingContract", Type: "address"},
+}
+
+func (wm *WalletManager) SignERC2612Permit(p *ERC2612Permit) (*PermitSignature, error) {
+    typedData := apitypes.TypedData{
+        Types: apitypes.Types{
+            "EIP712Domain": eip712DomainType,
+            "Permit": {
+                {Name: "owner", Type: "address"},
+                {Name: "spender", Type: "address"},
+                {Name: "value", Type: "uint256"},
+                {Name: "nonce", Type: "uint256"},
+                {Name: "deadline", Type: "uint256"},
+            },
+        },
+        PrimaryType: "Permit",
+        Domain:      wm.permitDomain(p.TokenName, p.TokenVersion, p.Token),
+        Message: apitypes.TypedDataMessage{
+            "owner":    wm.address.Hex(),
+            "spender":  p.Spender.Hex(),
+            "value":    p.Value.String(),
+            "nonce":    p.Nonce.String(),
+            "deadline": p.Deadline.String(),
+        },
+    }
+    return wm.signTypedData(typedData)
+}
+
+func (wm *WalletManager) SignPermit2(p *Permit2Single) (*PermitSignature, error) {
+    typedData := apitypes.TypedData{
+        Types: apitypes.Types{
+            // Permit2 的 domain 没有 version 字段
+            "EIP712Domain": {
+                {Name: "name", Type: "string"},
+                {Name: "chainId", Type: "uint256"},
+                {Name: "verifyingContract", Type: "address"},
+            },
+            "PermitSingle": {
+                {Name: "details", Type: "PermitDetails"},
+                {Name: "spender", Type: "address"},
+                {Name: "sigDeadline", Type: "uint256"},
+            },
+            "PermitDetails": {
+                {Name: "token", Type: "address"},
+                {Name: "amount", Type: "uint160"},
+                {Name: "expiration", Type: "uint48"},
+                {Name: "nonce", Type: "uint48"},
+            },
+        },
+        PrimaryType: "PermitSingle",
+        Domain: apitypes.TypedDataDomain{
+            Name:              "Permit2",
+            ChainId:           (*math.HexOrDecimal256)(wm.chainID),
+            VerifyingContract: p.Permit2.Hex(),
+        },
+        Message: apitypes.TypedDataMessage{
+            "details": map[string]interface{}{
+                "token":      p.Token.Hex(),
+                "amount":     p.Amount.String(),
+                "expiration": p.Expiration.String(),
+                "nonce":      p.Nonce.String(),
+            },
+            "spender":     p.Spender.Hex(),
+            "sigDeadline": p.SigDeadline.String(),
+        },
+    }
+    return wm.signTypedData(typedData)
+}
+
+// 按 EIP-2612 的 domain 计算 DOMAIN_SEPARATOR，用于和代币合约中的值比对
+func (wm *WalletManager) PermitDomainSeparator(name, version string, token common.Address) (common.Hash, error) {
+    typedData := apitypes.TypedData{
+        Types:  apitypes.Types{"EIP712Domain": eip712DomainType},
+        Domain: wm.permitDomain(name, version, token),
+    }
+    separator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+    if err != nil {
+        return common.Hash{}, fmt.Errorf("failed to hash permit domain: %w", err)
+    }
+    return common.BytesToHash(separator), nil
+}
+
+func (wm *WalletManager) permitDomain(name, version string, token common.Address) apitypes.TypedDataDomain {
+    return apitypes.TypedDataDomain{
+        Name:              name,
+        Version:           version,
+        ChainId:           (*math.HexOrDecimal256)(wm.chainID),
+        VerifyingContract: token.Hex(),
+    }
+}
+
+func (wm *WalletManager) signTypedData(typedData apitypes.TypedData) (*PermitSignature, error) {
+    hash, _, err := apitypes.TypedDataAndHash(typedData)
+    if err != nil {
+        return nil, fmt.Errorf("failed to hash typed data: %w", err)
+    }
+
+    sig, err := crypto.Sign(hash, wm.privateKey)
+    if err != nil {
+        return nil, fmt.Errorf("failed to sign typed data: %w", err)
+    }
+    // 合约端 ecrecover 需要 v 为 27/28
+    sig[crypto.RecoveryIDOffset] += 27
+
+    result := &PermitSignature{
+        V:         sig[crypto.RecoveryIDOffset],
+        Signature: sig,
+    }
+    copy(result.R[:], sig[:32])
+    copy(result.S[:], sig[32:64])
+    return result, nil
+}
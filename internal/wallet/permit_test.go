@@ -0,0 +1,125 @@
+package wallet
+
+import (
+    "math/big"
+    "testing"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/common/math"
+    "github.com/ethereum/go-ethereum/crypto"
+    "github.com/ethereum/go-ethereum/signer/core/apitypes"
+    "github.com/stretchr/testify/assert"
+)
+
+// EIP-712 规范示例中使用的私钥 keccak256("cow")
+func newTestWallet(t *testing.T) *WalletManager {
+    key, err := crypto.HexToECDSA("c85ef7d79691fe79573b1a7064c19c1a9819ebdbd1faaab1a8ec92344438aaf4")
+    assert.NoError(t, err)
+    return &WalletManager{
+        privateKey: key,
+        publicKey:  &key.PublicKey,
+        address:    crypto.PubkeyToAddress(key.PublicKey),
+        chainID:    big.NewInt(1),
+    }
+}
+
+func TestPermitDomainSeparator(t *testing.T) {
+    wm := newTestWallet(t)
+
+    // 与主网合约的 DOMAIN_SEPARATOR() 一致
+    usdc, err := wm.PermitDomainSeparator("USD Coin", "2", common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"))
+    assert.NoError(t, err)
+    assert.Equal(t, common.HexToHash("0x06c37168a7db5138defc7866392bb87a741f9b3d104deb5094588ce041cae335"), usdc)
+
+    dai, err := wm.PermitDomainSeparator("Dai Stablecoin", "1", common.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F"))
+    assert.NoError(t, err)
+    assert.Equal(t, common.HexToHash("0xdbb8cf42e1ecb028be3f3dbc922e1d878b963f411dc388ced501601c60f7c6f7"), dai)
+}
+
+func TestSignTypedData_EIP712Example(t *testing.T) {
+    wm := newTestWallet(t)
+    assert.Equal(t, common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"), wm.address)
+
+    typedData := apitypes.TypedData{
+        Types: apitypes.Types{
+            "EIP712Domain": eip712DomainType,
+            "Person": {
+                {Name: "name", Type: "string"},
+                {Name: "wallet", Type: "address"},
+            },
+            "Mail": {
+                {Name: "from", Type: "Person"},
+                {Name: "to", Type: "Person"},
+                {Name: "contents", Type: "string"},
+            },
+        },
+        PrimaryType: "Mail",
+        Domain: apitypes.TypedDataDomain{
+            Name:              "Ether Mail",
+            Version:           "1",
+            ChainId:           math.NewHexOrDecimal256(1),
+            VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC",
+        },
+        Message: apitypes.TypedDataMessage{
+            "from":     map[string]interface{}{"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+            "to":       map[string]interface{}{"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+            "contents": "Hello, Bob!",
+        },
+    }
+
+    sig, err := wm.signTypedData(typedData)
+    assert.NoError(t, err)
+    // v 调整为 27/28 后与规范给出的签名一致
+    assert.Equal(t, uint8(28), sig.V)
+    assert.Equal(t, common.HexToHash("0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d"), common.Hash(sig.R))
+    assert.Equal(t, common.HexToHash("0x07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562"), common.Hash(sig.S))
+    assert.Equal(t, sig.V, sig.Signature[64])
+}
+
+func TestSignERC2612Permit(t *testing.T) {
+    wm := newTestWallet(t)
+
+    sig, err := wm.SignERC2612Permit(&ERC2612Permit{
+        TokenName:    "USD Coin",
+        TokenVersion: "2",
+        Token:        common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"),
+        Spender:      common.HexToAddress("0x68b3465833fb72A70ecDF485E0e4C7bD8665Fc45"),
+        Value:        big.NewInt(1000000),
+        Nonce:        big.NewInt(0),
+        Deadline:     big.NewInt(1700000000),
+    })
+    assert.NoError(t, err)
+    assert.Contains(t, []uint8{27, 28}, sig.V)
+
+    // 独立计算的 keccak256(0x1901 || DOMAIN_SEPARATOR || hashStruct(Permit))，签名应能恢复出钱包地址
+    digest := common.HexToHash("0x1284e09065ef1f33fc0fd9ee50799303a9a1b02ae7546b354bed0dd675cd967f")
+    raw := append([]byte(nil), sig.Signature...)
+    raw[crypto.RecoveryIDOffset] -= 27
+    pub, err := crypto.SigToPub(digest.Bytes(), raw)
+    assert.NoError(t, err)
+    assert.Equal(t, wm.address, crypto.PubkeyToAddress(*pub))
+}
+
+func TestSignPermit2(t *testing.T) {
+    wm := newTestWallet(t)
+
+    sig, err := wm.SignPermit2(&Permit2Single{
+        Permit2:     common.HexToAddress("0x000000000022D473030F116dDEE9F6B43aC78BA3"),
+        Token:       common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"),
+        Spender:     common.HexToAddress("0x66a9893cC07D91D95644AEDD05D03f95e1dBA8Af"),
+        Amount:      big.NewInt(1000000),
+        Expiration:  big.NewInt(1700000000),
+        Nonce:       big.NewInt(0),
+        SigDeadline: big.NewInt(1700000000),
+    })
+    assert.NoError(t, err)
+    assert.Contains(t, []uint8{27, 28}, sig.V)
+
+    // domain 为主网 Permit2 的 DOMAIN_SEPARATOR (0x866a5aba...)，按 PERMIT_SINGLE_TYPEHASH 独立计算的 digest
+    digest := common.HexToHash("0xab5a01f79a1fbde219783545d104d96ca1ea9c440519f1b3dbfd8913e2f8b89f")
+    raw := append([]byte(nil), sig.Signature...)
+    raw[crypto.RecoveryIDOffset] -= 27
+    pub, err := crypto.SigToPub(digest.Bytes(), raw)
+    assert.NoError(t, err)
+    assert.Equal(t, wm.address, crypto.PubkeyToAddress(*pub))
+}
@@ -1,11 +1,21 @@
 package decimal
 
 import (
+    "fmt"
     "math/big"
 
     "github.com/shopspring/decimal"
 )
 
+type Decimal = decimal.Decimal
+
+var (
+    Zero          = decimal.Zero
+    NewFromInt    = decimal.NewFromInt
+    NewFromFloat  = decimal.NewFromFloat
+    NewFromBigInt = decimal.NewFromBigInt
+)
+
 var (
     WeiPerETH = decimal.NewFromBigInt(big.NewInt(1e18), 0)
 )
@@ -29,6 +39,15 @@ func FormatBalance(balance *big.Int, decimals int) decimal.Decimal {
     return balanceDec.Div(divisor)
 }
 
+// 按代币精度转换为最小单位，精度超出代币支持时返回错误
+func ToUnits(amount decimal.Decimal, decimals int) (*big.Int, error) {
+    units := amount.Shift(int32(decimals))
+    if !units.IsInteger() {
+        return nil, fmt.Errorf("amount %s has more than %d decimal places", amount.String(), decimals)
+    }
+    return units.BigInt(), nil
+}
+
 func ParseDecimal(value string) (decimal.Decimal, error) {
     return decimal.NewFromString(value)
 }
@@ -0,0 +1,42 @@
+package decimal_test
+
+import (
+    "math/big"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+
+    "github.com/your-username/ethereum-trading-mcp/pkg/decimal"
+)
+
+func TestToUnits(t *testing.T) {
+    // USDC 6 位精度
+    amount, err := decimal.ParseDecimal("100.5")
+    assert.NoError(t, err)
+    units, err := decimal.ToUnits(amount, 6)
+    assert.NoError(t, err)
+    assert.Equal(t, big.NewInt(100500000), units)
+
+    // 末尾的 0 不算多余精度
+    amount, err = decimal.ParseDecimal("1.500000000")
+    assert.NoError(t, err)
+    units, err = decimal.ToUnits(amount, 6)
+    assert.NoError(t, err)
+    assert.Equal(t, big.NewInt(1500000), units)
+
+    // 超出代币精度
+    amount, err = decimal.ParseDecimal("0.0000001")
+    assert.NoError(t, err)
+    _, err = decimal.ToUnits(amount, 6)
+    assert.Error(t, err)
+}
+
+func TestFormatBalanceRoundTrip(t *testing.T) {
+    units := big.NewInt(123456789)
+    formatted := decimal.FormatBalance(units, 6)
+    assert.Equal(t, "123.456789", formatted.String())
+
+    back, err := decimal.ToUnits(formatted, 6)
+    assert.NoError(t, err)
+    assert.Equal(t, units, back)
+}